@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"go-sensors-simulator/configs"
+	"go-sensors-simulator/pkg/data"
+	"go-sensors-simulator/pkg/models"
+	"go-sensors-simulator/pkg/mqtt"
+	"go-sensors-simulator/pkg/simulator"
+)
+
+// Leituras acumuladas antes de gravar no arquivo CSV do dia
+const csvBatchSize = 10000
+
+// dailyCSV grava as leituras e os seus rótulos nos arquivos CSV do dia de cada uma, trocando
+// de arquivo à meia-noite
+type dailyCSV struct {
+	dataDir string
+	date    time.Time // Dia dos arquivos abertos, zero antes da primeira leitura
+	storage *data.CSVStorage
+	labels  *data.LabelStorage
+	batch   []models.SensorReading // Inclui os registros das amostras descartadas, só para os rótulos
+	count   int                    // Leituras do dia atual
+}
+
+// Gera o histórico sintético de um período passado, o mais rápido que a CPU permitir.
+// Um único simulador percorre o período inteiro, de modo que o estado dos sensores, do tempo
+// e dos atuadores continua de um dia para o outro; o paralelismo vem dos shards de sensores.
+func main() {
+	configPath := flag.String("config", "configs/config.json", "Caminho para o arquivo de configuração")
+	from := flag.String("from", "", "Início do período (AAAA-MM-DD ou RFC3339)")
+	to := flag.String("to", "", "Fim do período, exclusivo (AAAA-MM-DD ou RFC3339)")
+	step := flag.Duration("step", 0, "Intervalo entre ciclos (padrão: simulation_rate da configuração)")
+	seed := flag.Int64("seed", 0, "Semente base (padrão: seed da configuração ou 1)")
+	out := flag.String("out", "", "Diretório dos arquivos CSV (padrão: data_dir da configuração)")
+	sinkNames := flag.String("sinks", "csv", "Destinos separados por vírgula (csv, mqtt)")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "Shards de sensores simulados em paralelo")
+	flag.Parse()
+
+	config, err := configs.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Erro ao carregar configuração: %v", err)
+	}
+
+	start, err := parseTime(*from)
+	if err != nil {
+		log.Fatalf("Erro no início do período: %v", err)
+	}
+	end, err := parseTime(*to)
+	if err != nil {
+		log.Fatalf("Erro no fim do período: %v", err)
+	}
+	if !start.Before(end) {
+		log.Fatalf("O fim do período (%s) deve ser posterior ao início (%s)", *to, *from)
+	}
+
+	if *step == 0 {
+		*step = config.SimulationRate
+	}
+	if *step <= 0 {
+		log.Fatalf("O intervalo entre ciclos deve ser positivo, recebido %v", *step)
+	}
+	if *seed == 0 {
+		*seed = config.Seed
+	}
+	if *seed == 0 {
+		*seed = 1
+	}
+	if *out == "" {
+		*out = config.DataDir
+	}
+	if *parallel < 1 {
+		*parallel = 1
+	}
+
+	// Preparar os destinos
+	var writeCSV bool
+	var mqttClient *mqtt.MQTTClient
+	for _, name := range strings.Split(*sinkNames, ",") {
+		switch strings.TrimSpace(name) {
+		case "csv":
+			writeCSV = true
+		case "mqtt":
+			mqttClient, err = mqtt.NewMQTTClient(config.MQTT)
+			if err != nil {
+				log.Fatalf("Erro ao criar cliente MQTT: %v", err)
+			}
+			if err := mqttClient.Connect(); err != nil {
+				log.Fatalf("Erro ao conectar ao broker MQTT: %v", err)
+			}
+			defer mqttClient.Disconnect()
+		default:
+			log.Fatalf("Destino desconhecido %q (use csv ou mqtt)", name)
+		}
+	}
+	if writeCSV {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			log.Fatalf("Erro ao criar diretório de dados: %v", err)
+		}
+	}
+
+	sensors := config.AllSensors()
+	days := int(math.Ceil(end.Sub(start).Hours() / 24))
+	log.Printf("Gerando %d dias (%s a %s) para %d sensores a cada %s com %d workers",
+		days, start.Format(time.RFC3339), end.Format(time.RFC3339), len(sensors), *step, *parallel)
+
+	began := time.Now()
+	total, err := backfill(config, sensors, start, end, *step, *seed, *parallel, *out, writeCSV, mqttClient)
+	elapsed := time.Since(began).Seconds()
+	fmt.Printf("leituras: %d (%.0f leituras/s)\n", total, float64(total)/elapsed)
+	if err != nil {
+		log.Fatalf("Erro ao gerar o histórico: %v", err)
+	}
+}
+
+// backfill simula o período [start, end) e entrega as leituras aos destinos, retornando quantas foram geradas
+func backfill(config configs.AppConfig, sensors []models.SensorConfig, start, end time.Time, step time.Duration,
+	seed int64, workers int, dataDir string, writeCSV bool, mqttClient *mqtt.MQTTClient) (int, error) {
+
+	// O primeiro Step avança o relógio até o início do período
+	clock := simulator.NewManualClock(start.Add(-step))
+	sim, err := simulator.NewSimulator(sensors, nil,
+		simulator.WithSeed(seed), simulator.WithWorkers(workers), simulator.WithClock(clock),
+		simulator.WithInterval(step), simulator.WithGrowRooms(config.GrowRooms),
+		simulator.WithSite(config.Site), simulator.WithWeather(config.Weather),
+		simulator.WithActuators(config.Actuators))
+	if err != nil {
+		return 0, fmt.Errorf("falha ao criar simulador: %w", err)
+	}
+
+	var csv *dailyCSV
+	if writeCSV {
+		csv = &dailyCSV{dataDir: dataDir}
+	}
+
+	// Entrega síncrona: cada ciclo é gravado antes do próximo
+	count := 0
+	var sinkErr error
+	// Os registros das amostras descartadas chegam apenas para os rótulos
+	_, err = sim.Subscribe("backfill", simulator.SubscriberConfig{IncludeDropped: true}, func(readings []models.SensorReading) {
+		delivered := withoutDropped(readings)
+		count += len(delivered)
+		if csv != nil {
+			if err := csv.store(readings); err != nil && sinkErr == nil {
+				sinkErr = err
+			}
+		}
+		if mqttClient != nil {
+			if err := mqttClient.PublishReadings(delivered); err != nil && sinkErr == nil {
+				sinkErr = fmt.Errorf("falha ao publicar via MQTT: %w", err)
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for clock.Now().Add(step).Before(end) {
+		if err := sim.Step(); err != nil {
+			return count, err
+		}
+		if sinkErr != nil {
+			return count, sinkErr
+		}
+	}
+	if csv != nil {
+		if err := csv.close(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// store acumula as leituras, abrindo o arquivo do dia seguinte quando a data muda
+func (w *dailyCSV) store(readings []models.SensorReading) error {
+	for _, reading := range readings {
+		t := reading.Timestamp.Local()
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		if !date.Equal(w.date) {
+			if err := w.close(); err != nil {
+				return err
+			}
+			if err := w.open(date); err != nil {
+				return err
+			}
+		}
+		w.batch = append(w.batch, reading)
+		if !reading.Dropped {
+			w.count++
+		}
+		if len(w.batch) >= csvBatchSize {
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// open cria os arquivos do dia, substituindo os existentes em vez de duplicar as leituras
+func (w *dailyCSV) open(date time.Time) error {
+	for _, path := range []string{data.DailyCSVPath(w.dataDir, date), data.DailyLabelsPath(w.dataDir, date)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("falha ao remover arquivo existente: %w", err)
+		}
+	}
+	storage, err := data.NewCSVStorageForDate(w.dataDir, date)
+	if err != nil {
+		return err
+	}
+	if err := storage.Initialize(); err != nil {
+		return err
+	}
+	labels, err := data.NewLabelStorageForDate(w.dataDir, date)
+	if err != nil {
+		return err
+	}
+	if err := labels.Initialize(); err != nil {
+		return err
+	}
+	w.date, w.storage, w.labels, w.count = date, storage, labels, 0
+	return nil
+}
+
+// flush grava as leituras acumuladas e os seus rótulos nos arquivos do dia
+func (w *dailyCSV) flush() error {
+	if w.storage == nil || len(w.batch) == 0 {
+		return nil
+	}
+	if err := w.storage.StoreReadings(withoutDropped(w.batch)); err != nil {
+		return err
+	}
+	if err := w.labels.StoreReadings(w.batch); err != nil {
+		return err
+	}
+	w.batch = w.batch[:0]
+	return nil
+}
+
+// close grava o restante do dia atual, incluindo os eventos ainda em andamento
+func (w *dailyCSV) close() error {
+	if w.storage == nil {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.labels.Close(); err != nil {
+		return err
+	}
+	log.Printf("Dia %s concluído: %d leituras", w.date.Format("2006-01-02"), w.count)
+	w.storage = nil
+	return nil
+}
+
+// withoutDropped retorna as leituras sem os registros de amostras descartadas
+func withoutDropped(readings []models.SensorReading) []models.SensorReading {
+	delivered := make([]models.SensorReading, 0, len(readings))
+	for _, reading := range readings {
+		if !reading.Dropped {
+			delivered = append(delivered, reading)
+		}
+	}
+	return delivered
+}
+
+// parseTime interpreta uma data (AAAA-MM-DD, meia-noite local) ou um instante RFC3339
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("informe a data")
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("data inválida %q (use AAAA-MM-DD ou RFC3339)", value)
+	}
+	return t.Local(), nil
+}
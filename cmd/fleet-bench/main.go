@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"go-sensors-simulator/configs"
+	"go-sensors-simulator/pkg/models"
+	"go-sensors-simulator/pkg/simulator"
+)
+
+// Mede a vazão do simulador para uma frota gerada por template (salas × sensores)
+func main() {
+	rooms := flag.Int("rooms", 200, "Número de salas (grupos do template)")
+	perRoom := flag.Int("per-room", 8, "Sensores por sala")
+	workers := flag.Int("workers", runtime.NumCPU(), "Shards processados em paralelo")
+	duration := flag.Duration("duration", 10*time.Second, "Duração da medição")
+	buffer := flag.Int("buffer", 4, "Ciclos enfileirados para o destino (0 = entrega síncrona)")
+	batch := flag.Int("batch", 1000, "Máximo de leituras por lote entregue")
+	sinkLatency := flag.Duration("sink-latency", 0, "Latência simulada do destino por lote")
+	seed := flag.Int64("seed", 1, "Semente da simulação")
+	flag.Parse()
+
+	// Montar o template repetindo os sensores padrão até completar cada sala
+	defaults := configs.DefaultConfig().Sensors
+	template := models.SensorTemplate{Prefix: "room", Count: *rooms}
+	for i := 0; i < *perRoom; i++ {
+		sensor := defaults[i%len(defaults)]
+		sensor.ID = fmt.Sprintf("%s%02d", sensor.Type, i+1)
+		template.Sensors = append(template.Sensors, sensor)
+	}
+	sensors := template.Expand()
+
+	var delivered, batches int64
+	callback := func(readings []models.SensorReading) {
+		if *sinkLatency > 0 {
+			time.Sleep(*sinkLatency)
+		}
+		atomic.AddInt64(&delivered, int64(len(readings)))
+		atomic.AddInt64(&batches, 1)
+	}
+
+	// Relógio manual avança um intervalo por ciclo, independente do ritmo real
+	clock := simulator.NewManualClock(time.Now())
+	sim, err := simulator.NewSimulator(sensors, callback,
+		simulator.WithSeed(*seed), simulator.WithClock(clock),
+		simulator.WithWorkers(*workers), simulator.WithAsyncDelivery(*buffer, *batch))
+	if err != nil {
+		log.Fatalf("Erro ao criar simulador: %v", err)
+	}
+
+	log.Printf("Simulando %d sensores (%d salas × %d) com %d workers por %s",
+		len(sensors), *rooms, *perRoom, *workers, *duration)
+
+	start := time.Now()
+	sim.Start(time.Nanosecond)
+	time.Sleep(*duration)
+	sim.Stop()
+	elapsed := time.Since(start).Seconds()
+
+	total := atomic.LoadInt64(&delivered)
+	fmt.Printf("sensores:          %d\n", len(sensors))
+	fmt.Printf("workers:           %d\n", *workers)
+	fmt.Printf("leituras entregues: %d (%.0f leituras/s)\n", total, float64(total)/elapsed)
+	fmt.Printf("ciclos entregues:  %.1f ciclos/s\n", float64(total)/float64(len(sensors))/elapsed)
+	fmt.Printf("lotes entregues:   %d\n", atomic.LoadInt64(&batches))
+	fmt.Printf("ciclos descartados: %d\n", sim.DroppedBatches())
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -25,6 +26,10 @@ import (
 func main() {
 	// Definir flags
 	configPath := flag.String("config", "configs/config.json", "Caminho para o arquivo de configuração")
+	replayPaths := flag.String("replay", "", "Reproduzir arquivos CSV (separados por vírgula) no lugar da simulação")
+	scenarioPath := flag.String("scenario", "", "Carregar e iniciar um cenário (JSON ou YAML)")
+	restorePath := flag.String("restore", "", "Retomar a simulação a partir de um snapshot (JSON)")
+	opcuaMode := flag.String("opcua-mode", "", "Modo de mapeamento OPC-UA (auto, prosys ou prosys-read); sobrepõe opcua.mapping_mode")
 	flag.Parse()
 
 	// Carregar configuração
@@ -33,8 +38,16 @@ func main() {
 		log.Fatalf("Erro ao carregar configuração: %v", err)
 	}
 
-	// Forçar modo de mapeamento para Prosys no modo de leitura
-	config.OPCUA.MappingMode = "prosys-read"
+	// Flag de reprodução sobrepõe a configuração
+	if *replayPaths != "" {
+		config.EnableReplay = true
+		config.Replay.Paths = strings.Split(*replayPaths, ",")
+	}
+
+	// Flag de modo de mapeamento OPC-UA sobrepõe a configuração
+	if *opcuaMode != "" {
+		config.OPCUA.MappingMode = *opcuaMode
+	}
 
 	// Criar diretório de dados se não existir
 	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
@@ -57,6 +70,7 @@ func main() {
 	var opcuaClient *opcua.OPCUAClient
 	var wireGuardManager *vpn.WireGuardManager
 	var csvStorage *data.CSVStorage
+	var labelStorage *data.LabelStorage
 
 	// Inicializar armazenamento CSV
 	if config.EnableCSVStore {
@@ -67,6 +81,17 @@ func main() {
 		if err := csvStorage.Initialize(); err != nil {
 			log.Fatalf("Erro ao inicializar arquivo CSV: %v", err)
 		}
+
+		// Eventos anômalos das leituras em um CSV separado; os em andamento são gravados no encerramento
+		labelStorage, err = data.NewLabelStorage(config.DataDir)
+		if err != nil {
+			log.Fatalf("Erro ao inicializar armazenamento de rótulos: %v", err)
+		}
+		defer func() {
+			if err := labelStorage.Close(); err != nil {
+				log.Printf("Erro ao gravar rótulos: %v", err)
+			}
+		}()
 	}
 
 	// Inicializar cliente MQTT
@@ -118,48 +143,248 @@ func main() {
 		}
 	}
 
-	// Criar função de callback para processar leituras de sensores
-	readingsHandler := func(readings []models.SensorReading) {
-		// Armazenar em CSV a cada intervalo configurado
-		if config.EnableCSVStore {
+	// Destinos das leituras; cada um assina o simulador com a sua própria fila, para que um
+	// destino lento não atrase os demais
+	type sink struct {
+		name     string
+		handler  func([]models.SensorReading)
+		withDrop bool // Recebe os registros das amostras descartadas
+	}
+	var sinks []sink
+
+	// Armazenar em CSV a cada intervalo configurado
+	if config.EnableCSVStore {
+		sinks = append(sinks, sink{name: "csv", handler: func(readings []models.SensorReading) {
 			if err := csvStorage.StoreReadings(readings); err != nil {
 				log.Printf("Erro ao armazenar leituras em CSV: %v", err)
 			}
-		}
+		}})
 
-		// Publicar via MQTT
-		if config.EnableMQTT && mqttClient != nil {
+		// Os rótulos incluem os das amostras descartadas por dropout, que não vão para os dados
+		sinks = append(sinks, sink{name: "labels", withDrop: true, handler: func(readings []models.SensorReading) {
+			if err := labelStorage.StoreReadings(readings); err != nil {
+				log.Printf("Erro ao armazenar rótulos em CSV: %v", err)
+			}
+		}})
+	}
+
+	// Publicar via MQTT
+	if config.EnableMQTT && mqttClient != nil {
+		sinks = append(sinks, sink{name: "mqtt", handler: func(readings []models.SensorReading) {
 			if err := mqttClient.PublishReadings(readings); err != nil {
 				log.Printf("Erro ao publicar leituras via MQTT: %v", err)
 			}
-		}
+		}})
+	}
 
-		// Publicar via OPC-UA
-		if config.EnableOPCUA && opcuaClient != nil {
+	// Publicar via OPC-UA
+	if config.EnableOPCUA && opcuaClient != nil {
+		sinks = append(sinks, sink{name: "opcua", handler: func(readings []models.SensorReading) {
 			if err := opcuaClient.WriteReadings(readings); err != nil {
 				log.Printf("Erro ao escrever leituras via OPC-UA: %v", err)
 			}
-		}
+		}})
 	}
 
 	// Criar simulador
-	sim := simulator.NewSimulator(config.Sensors, readingsHandler)
+	// Publicar o estado dos atuadores como as leituras dos sensores
+	actuatorHandler := func(states []models.ActuatorState) {
+		if config.EnableMQTT && mqttClient != nil {
+			if err := mqttClient.PublishActuatorStates(states); err != nil {
+				log.Printf("Erro ao publicar atuadores via MQTT: %v", err)
+			}
+		}
 
-	// Iniciar simulador em uma goroutine
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sim.Start(config.SimulationRate)
+		if config.EnableOPCUA && opcuaClient != nil {
+			if err := opcuaClient.WriteActuatorStates(states); err != nil {
+				log.Printf("Erro ao escrever atuadores via OPC-UA: %v", err)
+			}
+		}
+	}
 
-		// Loop para manter o simulador rodando até receber sinal de parada
-		<-ctx.Done()
-		log.Println("Parando simulador...")
-	}()
+	// Publicar os metadados de inventário dos sensores (tópicos MQTT retidos e propriedades OPC-UA)
+	publishMetadata := func(sensors []models.SensorConfig) {
+		if config.EnableMQTT && mqttClient != nil {
+			if err := mqttClient.PublishSensorMetadata(sensors); err != nil {
+				log.Printf("Erro ao publicar metadados via MQTT: %v", err)
+			}
+		}
+		if config.EnableOPCUA && opcuaClient != nil {
+			if err := opcuaClient.WriteSensorMetadata(sensors); err != nil {
+				log.Printf("Erro ao escrever metadados via OPC-UA: %v", err)
+			}
+		}
+	}
+
+	// Propagar sensores alterados pela API aos destinos e, se habilitado, ao arquivo de configuração
+	var persistMu sync.Mutex
+	sensorHandler := func(previous, current *models.SensorConfig) {
+		switch {
+		case previous == nil:
+			log.Printf("Sensor %s adicionado", current.ID)
+		case current == nil:
+			log.Printf("Sensor %s removido", previous.ID)
+		default:
+			log.Printf("Sensor %s alterado", current.ID)
+		}
+
+		// O tópico MQTT e o nó OPC-UA dependem do tipo e da hierarquia de ativos; descartar
+		// os do sensor anterior
+		if previous != nil && (current == nil || current.Type != previous.Type || current.Path() != previous.Path()) {
+			if config.EnableMQTT && mqttClient != nil {
+				if err := mqttClient.ClearSensorTopic(*previous); err != nil {
+					log.Printf("Erro ao limpar tópico MQTT do sensor %s: %v", previous.ID, err)
+				}
+			}
+			if config.EnableOPCUA && opcuaClient != nil {
+				opcuaClient.RemoveSensorNode(*previous)
+			}
+		}
+		if current != nil {
+			publishMetadata([]models.SensorConfig{*current})
+		}
+
+		if !config.PersistSensors {
+			return
+		}
+		persistMu.Lock()
+		defer persistMu.Unlock()
+		if !config.ApplySensorChange(previous, current) {
+			log.Printf("Aviso: sensor gerado por template não é gravado na configuração")
+			return
+		}
+		if err := configs.SaveConfig(*configPath, config); err != nil {
+			log.Printf("Erro ao salvar configuração: %v", err)
+		}
+	}
+
+	// Relógio virtual permite acelerar o tempo simulado (ajustável pela API)
+	timeSpeed := config.TimeSpeed
+	if timeSpeed <= 0 {
+		timeSpeed = 1
+	}
+	startTime := time.Now()
+	if config.StartTime != nil {
+		startTime = *config.StartTime
+	}
+	clock, err := simulator.NewVirtualClock(startTime, timeSpeed)
+	if err != nil {
+		log.Fatalf("Erro ao criar relógio da simulação: %v", err)
+	}
+
+	sim, err := simulator.NewSimulator(config.AllSensors(), nil,
+		simulator.WithSeed(config.Seed), simulator.WithClock(clock),
+		simulator.WithWorkers(config.Workers), simulator.WithGrowRooms(config.GrowRooms),
+		simulator.WithSite(config.Site), simulator.WithWeather(config.Weather),
+		simulator.WithActuators(config.Actuators), simulator.WithActuatorCallback(actuatorHandler),
+		simulator.WithSensorCallback(sensorHandler))
+	if err != nil {
+		log.Fatalf("Erro ao criar simulador: %v", err)
+	}
+
+	// Assinar as leituras com cada destino; as filas são esvaziadas no encerramento
+	for _, sink := range sinks {
+		sinkConfig := config.Sink(sink.name)
+		if sink.withDrop {
+			sinkConfig.IncludeDropped = true
+		}
+		subscription, err := sim.Subscribe(sink.name, sinkConfig, sink.handler)
+		if err != nil {
+			log.Fatalf("Erro ao assinar leituras para o destino %s: %v", sink.name, err)
+		}
+		defer subscription.Unsubscribe()
+	}
+
+	// Receber comandos de atuadores via MQTT e OPC-UA
+	if len(config.Actuators) > 0 {
+		commandHandler := func(actuatorID string, value float64) {
+			if err := sim.SetActuator(actuatorID, value); err != nil {
+				log.Printf("Comando de atuador rejeitado: %v", err)
+			}
+		}
+
+		if config.EnableMQTT && mqttClient != nil {
+			if err := mqttClient.SubscribeActuatorCommands(commandHandler); err != nil {
+				log.Printf("Aviso: não foi possível assinar comandos MQTT: %v", err)
+			}
+		}
+
+		if config.EnableOPCUA && opcuaClient != nil {
+			if err := opcuaClient.WatchActuatorCommands(config.Actuators, commandHandler); err != nil {
+				log.Printf("Aviso: não foi possível monitorar comandos OPC-UA: %v", err)
+			}
+		}
+	}
+	// Retomar de um snapshot, se informado
+	if *restorePath != "" {
+		snapshot, err := simulator.LoadSnapshotFile(*restorePath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar snapshot: %v", err)
+		}
+		if err := sim.Restore(snapshot); err != nil {
+			log.Fatalf("Erro ao restaurar snapshot: %v", err)
+		}
+		log.Printf("Simulação retomada do snapshot de %s (horário simulado %s)",
+			snapshot.CreatedAt.Format(time.RFC3339), snapshot.Now.Format(time.RFC3339))
+	}
+	log.Printf("Simulador iniciado com semente %d", sim.Seed())
+	publishMetadata(sim.Configs())
+
+	// Carregar cenário, se informado
+	if *scenarioPath != "" {
+		scenario, err := simulator.LoadScenarioFile(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar cenário: %v", err)
+		}
+		if err := sim.LoadScenario(scenario); err != nil {
+			log.Fatalf("Erro ao carregar cenário: %v", err)
+		}
+		if err := sim.StartScenario(); err != nil {
+			log.Fatalf("Erro ao iniciar cenário: %v", err)
+		}
+		log.Printf("Cenário %q iniciado com %d eventos", scenario.Name, len(scenario.Events))
+	}
+
+	router := web.NewRouter(sim, config)
+
+	if config.EnableReplay {
+		// Reproduzir leituras gravadas pelo hub do simulador, com as mesmas filas e políticas
+		// de descarte dos destinos e do stream da API
+		replayer, err := data.NewCSVReplayer(config.Replay, func(readings []models.SensorReading) {
+			sim.Publish(ctx, readings)
+		})
+		if err != nil {
+			log.Fatalf("Erro ao inicializar reprodução de CSV: %v", err)
+		}
+		router.SetReadingsSource(replayer)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Reproduzindo leituras de %v (velocidade %.2fx)", config.Replay.Paths, config.Replay.Speed)
+			if err := replayer.Run(ctx); err != nil && err != context.Canceled {
+				log.Printf("Erro na reprodução de CSV: %v", err)
+				return
+			}
+			log.Println("Reprodução de CSV encerrada")
+		}()
+	} else {
+		// Iniciar simulador em uma goroutine
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Executar o simulador até o contexto ser cancelado no encerramento
+			if err := sim.Run(ctx, config.SimulationRate); err != nil && err != context.Canceled {
+				log.Printf("Erro na execução do simulador: %v", err)
+			}
+			log.Println("Simulador parado")
+		}()
+	}
 
 	// Inicializar servidor web
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.ServerPort),
-		Handler: web.NewRouter(sim, config),
+		Handler: router,
 	}
 
 	// Iniciar servidor web em uma goroutine
@@ -2,13 +2,16 @@ package configs
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"time"
 
+	"go-sensors-simulator/pkg/data"
 	"go-sensors-simulator/pkg/models"
 	"go-sensors-simulator/pkg/mqtt"
 	"go-sensors-simulator/pkg/opcua"
+	"go-sensors-simulator/pkg/simulator"
 	"go-sensors-simulator/pkg/vpn"
 )
 
@@ -17,11 +20,41 @@ type AppConfig struct {
 	// Configurações gerais
 	ServerPort      int           `json:"server_port"`
 	DataDir         string        `json:"data_dir"`
-	SimulationRate  time.Duration `json:"simulation_rate"`  // Intervalo entre leituras em segundos
-	StorageInterval time.Duration `json:"storage_interval"` // Intervalo para salvar em CSV
+	SimulationRate  time.Duration `json:"simulation_rate"`      // Intervalo entre leituras em segundos
+	StorageInterval time.Duration `json:"storage_interval"`     // Intervalo para salvar em CSV
+	Seed            int64         `json:"seed"`                 // Semente da simulação (0 = aleatória)
+	TimeSpeed       float64       `json:"time_speed"`           // Multiplicador do tempo simulado (1 = tempo real)
+	StartTime       *time.Time    `json:"start_time,omitempty"` // Início do tempo simulado (padrão: agora)
 
 	// Sensores
-	Sensors []models.SensorConfig `json:"sensors"`
+	Sensors         []models.SensorConfig   `json:"sensors"`
+	SensorTemplates []models.SensorTemplate `json:"sensor_templates,omitempty"` // Grupos repetidos de sensores
+
+	// Hierarquia de ativos (locais → salas → dispositivos → sensores); define o caminho dos
+	// sensores listados que não informam "asset"
+	Assets []models.Site `json:"assets,omitempty"`
+
+	// Salas de cultivo com modelo físico acoplado (gerador "grow_room")
+	GrowRooms []simulator.GrowRoomConfig `json:"grow_rooms,omitempty"`
+
+	// Local da simulação para o modelo solar (geradores "solar" e "solar_temperature")
+	Site *simulator.SiteConfig `json:"site,omitempty"`
+
+	// Regimes do tempo (céu limpo, nublado, frente fria, onda de calor) que modulam
+	// temperatura, umidade, pressão e luminosidade em conjunto
+	Weather *simulator.WeatherConfig `json:"weather,omitempty"`
+
+	// Atuadores virtuais comandados via API, MQTT e OPC-UA
+	Actuators []models.ActuatorConfig `json:"actuators,omitempty"`
+
+	// Simulação de frotas grandes
+	Workers       int `json:"workers"`         // Shards de sensores processados em paralelo
+	SinkBuffer    int `json:"sink_buffer"`     // Ciclos enfileirados para os destinos (0 = entrega síncrona)
+	SinkBatchSize int `json:"sink_batch_size"` // Máximo de leituras por lote entregue (0 = sem limite)
+
+	// Fila de cada destino ("csv", "mqtt", "opcua"); destinos omitidos usam sink_buffer e
+	// sink_batch_size, descartando o ciclo mais novo com a fila cheia
+	Sinks map[string]simulator.SubscriberConfig `json:"sinks,omitempty"`
 
 	// Configurações MQTT
 	MQTT mqtt.MQTTConfig `json:"mqtt"`
@@ -32,11 +65,18 @@ type AppConfig struct {
 	// Configurações VPN
 	WireGuard vpn.WireGuardConfig `json:"wireguard"`
 
+	// Reprodução de arquivos CSV gravados no lugar da simulação
+	Replay data.ReplayConfig `json:"replay"`
+
 	// Habilitar/Desabilitar componentes
 	EnableMQTT     bool `json:"enable_mqtt"`
 	EnableOPCUA    bool `json:"enable_opcua"`
 	EnableVPN      bool `json:"enable_vpn"`
 	EnableCSVStore bool `json:"enable_csv_store"`
+	EnableReplay   bool `json:"enable_replay"`
+
+	// Gravar no arquivo de configuração os sensores adicionados, alterados ou removidos pela API
+	PersistSensors bool `json:"persist_sensors"`
 }
 
 // DefaultConfig retorna a configuração padrão
@@ -46,6 +86,9 @@ func DefaultConfig() AppConfig {
 		DataDir:         "./data",
 		SimulationRate:  1 * time.Second,
 		StorageInterval: 5 * time.Second,
+		TimeSpeed:       1,
+		Workers:         1,
+		SinkBuffer:      16,
 		EnableMQTT:      true,
 		EnableOPCUA:     true,
 		EnableVPN:       false,
@@ -112,7 +155,65 @@ func DefaultConfig() AppConfig {
 			AllowedIPs:    "10.0.0.0/24",
 			ConfigPath:    "/etc/wireguard/wg0.conf",
 		},
+		Replay: data.ReplayConfig{
+			Speed: 1,
+		},
+	}
+}
+
+// AllSensors retorna os sensores listados mais os gerados pelos templates, com o caminho
+// na hierarquia de ativos preenchido a partir de "assets"
+func (c AppConfig) AllSensors() []models.SensorConfig {
+	sensors := make([]models.SensorConfig, 0, len(c.Sensors))
+	sensors = append(sensors, c.Sensors...)
+	for _, template := range c.SensorTemplates {
+		sensors = append(sensors, template.Expand()...)
+	}
+
+	// A hierarquia é validada em LoadConfig; aqui um erro apenas deixa os caminhos vazios
+	paths, _ := models.AssetPaths(c.Assets)
+	for i, sensor := range sensors {
+		if path, ok := paths[sensor.ID]; ok && sensor.Asset == nil {
+			sensors[i].Asset = &path
+		}
 	}
+	return sensors
+}
+
+// Sink retorna a configuração da fila do destino informado
+func (c AppConfig) Sink(name string) simulator.SubscriberConfig {
+	if sink, ok := c.Sinks[name]; ok {
+		return sink
+	}
+	return simulator.SubscriberConfig{
+		Buffer:    c.SinkBuffer,
+		BatchSize: c.SinkBatchSize,
+		Overflow:  simulator.DropNewest,
+	}
+}
+
+// ApplySensorChange aplica à lista de sensores uma alteração feita em execução: current é
+// adicionado (previous nil), substitui previous ou, se nil, previous é removido. Sensores
+// gerados por templates não são alterados, para não gravar o template expandido; nesse caso
+// retorna false.
+func (c *AppConfig) ApplySensorChange(previous, current *models.SensorConfig) bool {
+	if previous == nil {
+		c.Sensors = append(c.Sensors, *current)
+		return true
+	}
+
+	for i, sensor := range c.Sensors {
+		if sensor.ID != previous.ID {
+			continue
+		}
+		if current == nil {
+			c.Sensors = append(c.Sensors[:i], c.Sensors[i+1:]...)
+		} else {
+			c.Sensors[i] = *current
+		}
+		return true
+	}
+	return false
 }
 
 // LoadConfig carrega a configuração de um arquivo
@@ -134,8 +235,22 @@ func LoadConfig(filepath string) (AppConfig, error) {
 	}
 
 	// Desserializar
-	err = json.Unmarshal(data, &config)
-	return config, err
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, err
+	}
+
+	// Validar os templates de sensores
+	for _, template := range config.SensorTemplates {
+		if err := template.Validate(); err != nil {
+			return config, err
+		}
+	}
+
+	// Validar a hierarquia de ativos
+	if _, err := models.AssetPaths(config.Assets); err != nil {
+		return config, fmt.Errorf("hierarquia de ativos inválida: %w", err)
+	}
+	return config, nil
 }
 
 // SaveConfig salva a configuração em um arquivo
@@ -6,6 +6,7 @@ require (
 	github.com/a-h/templ v0.3.865
 	github.com/eclipse/paho.mqtt.golang v1.5.0
 	github.com/gopcua/opcua v0.8.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
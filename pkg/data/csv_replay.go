@@ -0,0 +1,324 @@
+package data
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// ReplayConfig contém as configurações de reprodução de arquivos CSV gravados
+type ReplayConfig struct {
+	Paths     []string `json:"paths"`      // Arquivos, diretórios ou padrões glob com os CSVs
+	Speed     float64  `json:"speed"`      // Fator de velocidade (1 = ritmo original)
+	Loop      bool     `json:"loop"`       // Reiniciar ao chegar ao fim dos dados
+	Rebase    bool     `json:"rebase"`     // Reescrever timestamps para o horário da reprodução
+	SensorIDs []string `json:"sensor_ids"` // Reproduzir apenas estes sensores (vazio = todos)
+
+	// Preencher as lacunas de cada sensor repetindo o último valor, com qualidade
+	// UncertainNoCommunicationLastUsableValue, a cada intervalo típico do sensor
+	FillGaps bool            `json:"fill_gaps"`
+	MaxGap   models.Duration `json:"max_gap,omitempty"` // Intervalo considerado lacuna (padrão: 3× o intervalo típico)
+}
+
+// CSVReplayer reproduz leituras gravadas em CSV como uma fonte de leituras ao vivo
+type CSVReplayer struct {
+	config   ReplayConfig
+	batches  [][]models.SensorReading // Leituras agrupadas por timestamp, em ordem
+	callback func([]models.SensorReading)
+
+	mu       sync.Mutex
+	readings []models.SensorReading
+}
+
+// NewCSVReplayer carrega os arquivos CSV e cria um reprodutor de leituras
+func NewCSVReplayer(config ReplayConfig, callback func([]models.SensorReading)) (*CSVReplayer, error) {
+	if config.Speed <= 0 {
+		config.Speed = 1
+	}
+
+	files, err := resolveReplayFiles(config.Paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo CSV encontrado para reprodução")
+	}
+
+	filter := make(map[string]bool, len(config.SensorIDs))
+	for _, id := range config.SensorIDs {
+		filter[id] = true
+	}
+
+	var readings []models.SensorReading
+	for _, file := range files {
+		fileReadings, err := ReadCSVFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, reading := range fileReadings {
+			if len(filter) > 0 && !filter[reading.SensorID] {
+				continue
+			}
+			readings = append(readings, reading)
+		}
+	}
+	if len(readings) == 0 {
+		return nil, fmt.Errorf("nenhuma leitura encontrada para reprodução")
+	}
+	if config.FillGaps {
+		readings = fillGaps(readings, time.Duration(config.MaxGap))
+	}
+
+	return &CSVReplayer{
+		config:   config,
+		batches:  groupByTimestamp(readings),
+		callback: callback,
+	}, nil
+}
+
+// resolveReplayFiles expande diretórios e padrões glob em uma lista ordenada de arquivos
+func resolveReplayFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			matches, err := filepath.Glob(filepath.Join(path, "sensor_data_*.csv"))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+			continue
+		}
+		if err == nil {
+			files = append(files, path)
+			continue
+		}
+
+		matches, globErr := filepath.Glob(path)
+		if globErr != nil || len(matches) == 0 {
+			return nil, fmt.Errorf("falha ao localizar arquivos de reprodução %q: %w", path, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadCSVFile lê as leituras de um arquivo CSV no formato gravado por CSVStorage.
+// As colunas são identificadas pelo cabeçalho, então a ordem pode variar.
+func ReadCSVFile(path string) ([]models.SensorReading, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir arquivo CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler cabeçalho CSV de %s: %w", path, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, required := range []string{"timestamp", "sensor_id", "value"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("coluna %q ausente em %s", required, path)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var readings []models.SensorReading
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler %s linha %d: %w", path, line, err)
+		}
+
+		timestamp, err := time.Parse(time.RFC3339Nano, field(record, "timestamp"))
+		if err != nil {
+			return nil, fmt.Errorf("timestamp inválido em %s linha %d: %w", path, line, err)
+		}
+		value, err := strconv.ParseFloat(field(record, "value"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido em %s linha %d: %w", path, line, err)
+		}
+
+		reading := models.SensorReading{
+			SensorID:   field(record, "sensor_id"),
+			SensorType: models.SensorType(field(record, "sensor_type")),
+			Value:      value,
+			Unit:       field(record, "unit"),
+			Timestamp:  timestamp,
+		}
+		// Colunas da hierarquia de ativos são opcionais (arquivos antigos não as têm)
+		asset := models.AssetPath{Site: field(record, "site"), Room: field(record, "room"), Device: field(record, "device")}
+		if asset != (models.AssetPath{}) {
+			reading.Asset = &asset
+		}
+		if reading.Quality, err = models.ParseQuality(field(record, "quality")); err != nil {
+			return nil, fmt.Errorf("%w em %s linha %d", err, path, line)
+		}
+		readings = append(readings, reading)
+	}
+
+	return readings, nil
+}
+
+// fillGaps insere, nas lacunas de cada sensor maiores que maxGap (0 = 3× o intervalo
+// típico do sensor), leituras com o último valor recebido a cada intervalo típico
+func fillGaps(readings []models.SensorReading, maxGap time.Duration) []models.SensorReading {
+	sort.SliceStable(readings, func(i, j int) bool {
+		return readings[i].Timestamp.Before(readings[j].Timestamp)
+	})
+	series := make(map[string][]models.SensorReading)
+	for _, reading := range readings {
+		series[reading.SensorID] = append(series[reading.SensorID], reading)
+	}
+
+	filled := readings
+	for _, sensorReadings := range series {
+		interval := typicalInterval(sensorReadings)
+		if interval <= 0 {
+			continue
+		}
+		threshold := maxGap
+		if threshold <= 0 {
+			threshold = 3 * interval
+		}
+
+		for i := 1; i < len(sensorReadings); i++ {
+			prev, next := sensorReadings[i-1], sensorReadings[i]
+			if next.Timestamp.Sub(prev.Timestamp) <= threshold {
+				continue
+			}
+			for at := prev.Timestamp.Add(interval); next.Timestamp.Sub(at) >= interval/2; at = at.Add(interval) {
+				substitute := prev
+				substitute.Timestamp = at
+				substitute.Quality = models.QualityUncertainNoCommunicationLastUsableValue
+				substitute.Faults = nil
+				substitute.Labels = nil
+				filled = append(filled, substitute)
+			}
+		}
+	}
+	return filled
+}
+
+// typicalInterval retorna a mediana dos intervalos entre leituras consecutivas (ordenadas)
+func typicalInterval(readings []models.SensorReading) time.Duration {
+	var intervals []time.Duration
+	for i := 1; i < len(readings); i++ {
+		if d := readings[i].Timestamp.Sub(readings[i-1].Timestamp); d > 0 {
+			intervals = append(intervals, d)
+		}
+	}
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+// groupByTimestamp ordena as leituras e as agrupa em lotes com o mesmo timestamp
+func groupByTimestamp(readings []models.SensorReading) [][]models.SensorReading {
+	sort.SliceStable(readings, func(i, j int) bool {
+		return readings[i].Timestamp.Before(readings[j].Timestamp)
+	})
+
+	var batches [][]models.SensorReading
+	for i, reading := range readings {
+		if i == 0 || !reading.Timestamp.Equal(readings[i-1].Timestamp) {
+			batches = append(batches, nil)
+		}
+		last := len(batches) - 1
+		batches[last] = append(batches[last], reading)
+	}
+	return batches
+}
+
+// Run reproduz as leituras até o fim dos dados (ou indefinidamente, com Loop) ou até
+// o contexto ser cancelado. Os intervalos originais são divididos pelo fator de velocidade.
+func (r *CSVReplayer) Run(ctx context.Context) error {
+	first := r.batches[0][0].Timestamp
+	last := r.batches[len(r.batches)-1][0].Timestamp
+
+	// Intervalo entre o fim de uma volta e o início da próxima
+	loopGap := time.Second
+	if len(r.batches) > 1 {
+		loopGap = r.batches[1][0].Timestamp.Sub(first)
+	}
+	cycle := last.Sub(first) + loopGap
+
+	start := time.Now().Round(0) // Sem leitura monotônica nos timestamps reescritos
+	for loop := 0; ; loop++ {
+		for _, batch := range r.batches {
+			// Aguardar até o instante da leitura na escala de tempo da reprodução
+			offset := batch[0].Timestamp.Sub(first) + time.Duration(loop)*cycle
+			emitAt := start.Add(time.Duration(float64(offset) / r.config.Speed))
+			if wait := time.Until(emitAt); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			} else if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			readings := make([]models.SensorReading, len(batch))
+			copy(readings, batch)
+			if r.config.Rebase {
+				for i := range readings {
+					readings[i].Timestamp = emitAt
+				}
+			}
+			r.emit(readings)
+		}
+
+		if !r.config.Loop {
+			return nil
+		}
+	}
+}
+
+// emit armazena o lote como leituras mais recentes e notifica o callback
+func (r *CSVReplayer) emit(readings []models.SensorReading) {
+	r.mu.Lock()
+	r.readings = readings
+	r.mu.Unlock()
+
+	if r.callback != nil {
+		r.callback(readings)
+	}
+}
+
+// GetReadings retorna o lote reproduzido mais recentemente
+func (r *CSVReplayer) GetReadings() []models.SensorReading {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readings
+}
@@ -3,9 +3,13 @@ package data
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,21 +18,24 @@ import (
 
 // CSVStorage gerencia o armazenamento de dados em arquivo CSV
 type CSVStorage struct {
-	filePath    string
-	mu          sync.Mutex
-	initialized bool
+	filePath string
+	mu       sync.Mutex
 }
 
 // NewCSVStorage cria uma nova instância de armazenamento CSV
 func NewCSVStorage(dataDir string) (*CSVStorage, error) {
+	return NewCSVStorageForDate(dataDir, time.Now())
+}
+
+// NewCSVStorageForDate cria um armazenamento CSV no arquivo do dia informado
+func NewCSVStorageForDate(dataDir string, date time.Time) (*CSVStorage, error) {
 	// Verificar se o diretório existe, se não, criar
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("falha ao criar diretório de dados: %w", err)
 	}
 
-	// Gerar nome de arquivo baseado na data e hora atual
-	timestamp := time.Now().Format("2006-01-02")
-	filePath := filepath.Join(dataDir, fmt.Sprintf("sensor_data_%s.csv", timestamp))
+	// Gerar nome de arquivo baseado na data
+	filePath := DailyCSVPath(dataDir, date)
 
 	storage := &CSVStorage{
 		filePath: filePath,
@@ -37,33 +44,126 @@ func NewCSVStorage(dataDir string) (*CSVStorage, error) {
 	return storage, nil
 }
 
-// Initialize inicializa o arquivo CSV com o cabeçalho
+// DailyCSVPath retorna o caminho do arquivo CSV do dia informado
+func DailyCSVPath(dataDir string, date time.Time) string {
+	return filepath.Join(dataDir, fmt.Sprintf("sensor_data_%s.csv", date.Format("2006-01-02")))
+}
+
+// csvHeader são as colunas gravadas por CSVStorage, na ordem em que são escritas
+var csvHeader = []string{"timestamp", "sensor_id", "sensor_type", "value", "unit", "site", "room", "device", "quality"}
+
+// Initialize inicializa o arquivo CSV com o cabeçalho. Um arquivo existente gravado com
+// outras colunas (versões anteriores) é migrado para o cabeçalho atual.
 func (s *CSVStorage) Initialize() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.initializeLocked()
+}
 
-	// Verificar se o arquivo já existe
-	_, err := os.Stat(s.filePath)
-	fileExists := !os.IsNotExist(err)
+// initializeLocked garante o cabeçalho atual no arquivo; deve ser chamado com s.mu travado
+func (s *CSVStorage) initializeLocked() error {
+	header, err := readCSVHeader(s.filePath)
+	switch {
+	case os.IsNotExist(err) || (err == nil && header == nil):
+		// Arquivo novo (ou vazio): escrever o cabeçalho
+		if err := writeCSVFile(s.filePath, nil); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case !slices.Equal(header, csvHeader):
+		if err := migrateCSVFile(s.filePath, header); err != nil {
+			return err
+		}
+		log.Printf("Aviso: arquivo CSV %s gravado com colunas antigas (%s), migrado para o formato atual",
+			s.filePath, strings.Join(header, ","))
+	}
+	return nil
+}
 
-	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// readCSVHeader lê o cabeçalho de um arquivo CSV; retorna nil se o arquivo estiver vazio
+func readCSVHeader(path string) ([]string, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("falha ao abrir arquivo CSV: %w", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	// Se o arquivo não existir, escrever o cabeçalho
-	if !fileExists {
-		writer := csv.NewWriter(file)
-		defer writer.Flush()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler cabeçalho CSV de %s: %w", path, err)
+	}
+	return header, nil
+}
 
-		header := []string{"timestamp", "sensor_id", "sensor_type", "value", "unit"}
-		if err := writer.Write(header); err != nil {
-			return fmt.Errorf("falha ao escrever cabeçalho CSV: %w", err)
+// migrateCSVFile regrava um arquivo com o cabeçalho atual, levando cada coluna antiga para
+// a coluna de mesmo nome; colunas que o arquivo não tinha ficam vazias. Linhas mais largas
+// que o cabeçalho antigo, acrescentadas por versões que não migravam o arquivo, são lidas
+// com as colunas atuais.
+func migrateCSVFile(path string, header []string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("falha ao abrir arquivo CSV: %w", err)
+	}
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("falha ao ler arquivo CSV %s: %w", path, err)
+	}
+
+	prefix := len(header) <= len(csvHeader) && slices.Equal(header, csvHeader[:len(header)])
+	migrated := make([][]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		columns := header
+		if prefix && len(record) > len(header) && len(record) <= len(csvHeader) {
+			columns = csvHeader[:len(record)]
 		}
+		values := make(map[string]string, len(columns))
+		for i, name := range columns {
+			if i < len(record) {
+				values[name] = record[i]
+			}
+		}
+		row := make([]string, len(csvHeader))
+		for i, name := range csvHeader {
+			row[i] = values[name]
+		}
+		migrated = append(migrated, row)
 	}
+	return writeCSVFile(path, migrated)
+}
 
-	s.initialized = true
+// writeCSVFile grava o arquivo com o cabeçalho atual e as linhas informadas, substituindo-o
+// apenas depois de escrito por completo
+func writeCSVFile(path string, records [][]string) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("falha ao criar arquivo CSV: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(csvHeader); err != nil {
+		file.Close()
+		return fmt.Errorf("falha ao escrever cabeçalho CSV: %w", err)
+	}
+	if err := writer.WriteAll(records); err != nil {
+		file.Close()
+		return fmt.Errorf("falha ao escrever arquivo CSV: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("falha ao escrever arquivo CSV: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("falha ao substituir arquivo CSV: %w", err)
+	}
 	return nil
 }
 
@@ -72,11 +172,10 @@ func (s *CSVStorage) StoreReadings(readings []models.SensorReading) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Verificar se o armazenamento foi inicializado
-	if !s.initialized {
-		if err := s.Initialize(); err != nil {
-			return err
-		}
+	// Conferir o cabeçalho a cada gravação: se o arquivo foi removido ou substituído por
+	// outro com colunas diferentes, ele é recriado ou migrado antes de receber as linhas
+	if err := s.initializeLocked(); err != nil {
+		return err
 	}
 
 	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -89,12 +188,20 @@ func (s *CSVStorage) StoreReadings(readings []models.SensorReading) error {
 	defer writer.Flush()
 
 	for _, reading := range readings {
+		path := reading.Path()
 		record := []string{
-			reading.Timestamp.Format(time.RFC3339),
+			reading.Timestamp.Format(time.RFC3339Nano),
 			reading.SensorID,
 			string(reading.SensorType),
-			strconv.FormatFloat(reading.Value, 'f', 2, 64),
+			strconv.FormatFloat(reading.Value, 'f', -1, 64),
 			reading.Unit,
+			path.Site,
+			path.Room,
+			path.Device,
+			reading.Quality.String(),
+		}
+		if len(record) != len(csvHeader) {
+			return fmt.Errorf("falha ao escrever leitura no CSV: %d colunas, cabeçalho com %d", len(record), len(csvHeader))
 		}
 
 		if err := writer.Write(record); err != nil {
@@ -0,0 +1,189 @@
+package data
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+var testDate = time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC)
+
+// newTestStorage cria um armazenamento CSV inicializado em um diretório temporário
+func newTestStorage(t *testing.T, dir string) *CSVStorage {
+	t.Helper()
+	storage, err := NewCSVStorageForDate(dir, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	return storage
+}
+
+func TestCSVStorageSubSecondTimestamps(t *testing.T) {
+	dir := t.TempDir()
+	storage := newTestStorage(t, dir)
+
+	// Amostras a cada 250 ms, gravadas fora de ordem
+	base := testDate.Add(12 * time.Hour)
+	var readings []models.SensorReading
+	for _, ms := range []int{750, 0, 500, 250} {
+		readings = append(readings, models.SensorReading{
+			SensorID:   "temp001",
+			SensorType: models.Temperature,
+			Value:      float64(ms),
+			Timestamp:  base.Add(time.Duration(ms) * time.Millisecond),
+		})
+	}
+	if err := storage.StoreReadings(readings); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewCSVReplayer(ReplayConfig{Paths: []string{dir}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayer.batches) != 4 {
+		t.Fatalf("esperados 4 instantes distintos, encontrados %d", len(replayer.batches))
+	}
+	for i, batch := range replayer.batches {
+		want := base.Add(time.Duration(i*250) * time.Millisecond)
+		if !batch[0].Timestamp.Equal(want) || batch[0].Value != float64(i*250) {
+			t.Errorf("lote %d: %v (%v), esperado %v", i, batch[0].Timestamp, batch[0].Value, want)
+		}
+	}
+}
+
+func TestCSVStorageKeepsValuePrecision(t *testing.T) {
+	dir := t.TempDir()
+	storage := newTestStorage(t, dir)
+
+	// Valores de sensores com resolução fina não podem ser arredondados ao gravar
+	values := []float64{0.001, 21.345, 1013.2567, -0.125}
+	var readings []models.SensorReading
+	for i, value := range values {
+		readings = append(readings, models.SensorReading{
+			SensorID:   "s1",
+			SensorType: models.Temperature,
+			Value:      value,
+			Timestamp:  testDate.Add(time.Duration(i) * time.Second),
+		})
+	}
+	if err := storage.StoreReadings(readings); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewCSVReplayer(ReplayConfig{Paths: []string{dir}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, batch := range replayer.batches {
+		if batch[0].Value != values[i] {
+			t.Errorf("valor %d = %v, esperado %v", i, batch[0].Value, values[i])
+		}
+	}
+}
+
+func TestCSVStorageMigratesOldSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := DailyCSVPath(dir, testDate)
+
+	// Arquivo de uma versão anterior, sem as colunas da hierarquia de ativos, já com uma
+	// linha acrescentada no formato novo por uma versão que não migrava o arquivo
+	old := "timestamp,sensor_id,sensor_type,value,unit\n" +
+		"2025-05-15T12:00:00Z,temp001,temperature,21.50,°C\n" +
+		"2025-05-15T12:00:01Z,temp001,temperature,21.60,°C,sp01,flora,rack1\n"
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := newTestStorage(t, dir)
+	asset := &models.AssetPath{Site: "sp01", Room: "flora", Device: "rack2"}
+	err := storage.StoreReadings([]models.SensorReading{{
+		SensorID:   "temp001",
+		SensorType: models.Temperature,
+		Value:      21.7,
+		Unit:       "°C",
+		Timestamp:  testDate.Add(12*time.Hour + 2*time.Second),
+		Asset:      asset,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := readCSVHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(header, csvHeader) {
+		t.Fatalf("cabeçalho %v, esperado %v", header, csvHeader)
+	}
+
+	readings, err := ReadCSVFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*models.AssetPath{nil, {Site: "sp01", Room: "flora", Device: "rack1"}, asset}
+	if len(readings) != len(want) {
+		t.Fatalf("esperadas %d leituras, lidas %d", len(want), len(readings))
+	}
+	for i, reading := range readings {
+		if !reflect.DeepEqual(reading.Asset, want[i]) {
+			t.Errorf("leitura %d: ativo %v, esperado %v", i, reading.Asset, want[i])
+		}
+	}
+}
+
+func TestCSVStorageAddsQualityColumn(t *testing.T) {
+	dir := t.TempDir()
+	path := DailyCSVPath(dir, testDate)
+
+	// Arquivo com a hierarquia de ativos, mas anterior à coluna de qualidade
+	old := "timestamp,sensor_id,sensor_type,value,unit,site,room,device\n" +
+		"2025-05-15T12:00:00Z,temp001,temperature,21.50,°C,sp01,flora,rack1\n"
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := newTestStorage(t, dir)
+	reading := models.SensorReading{
+		SensorID:   "temp001",
+		SensorType: models.Temperature,
+		Value:      21.6,
+		Timestamp:  testDate.Add(12*time.Hour + time.Second),
+		Quality:    models.QualityUncertainSensorNotAccurate,
+	}
+	if err := storage.StoreReadings([]models.SensorReading{reading}); err != nil {
+		t.Fatal(err)
+	}
+
+	readings, err := ReadCSVFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Quality{models.QualityGood, models.QualityUncertainSensorNotAccurate}
+	if len(readings) != len(want) {
+		t.Fatalf("esperadas %d leituras, lidas %d", len(want), len(readings))
+	}
+	for i, reading := range readings {
+		if reading.Quality != want[i] {
+			t.Errorf("leitura %d: qualidade %v, esperada %v", i, reading.Quality, want[i])
+		}
+	}
+
+	// O arquivo removido depois da inicialização é recriado com o cabeçalho
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.StoreReadings([]models.SensorReading{reading}); err != nil {
+		t.Fatal(err)
+	}
+	if header, err := readCSVHeader(path); err != nil || !slices.Equal(header, csvHeader) {
+		t.Fatalf("cabeçalho %v (%v), esperado %v", header, err, csvHeader)
+	}
+}
@@ -0,0 +1,203 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// LabelEvent é um trecho contínuo de leituras de um sensor marcadas com o mesmo rótulo
+type LabelEvent struct {
+	SensorID   string
+	SensorType models.SensorType
+	Label      models.AnomalyLabel
+	Start      time.Time // Primeira leitura com o rótulo
+	End        time.Time // Última leitura com o rótulo
+}
+
+// LabelStorage grava em CSV os eventos anômalos das leituras, um por linha, com o tipo
+// do evento, início e fim. Leituras consecutivas de um sensor com o mesmo rótulo formam
+// um único evento, gravado quando uma leitura sem o rótulo o encerra ou em Close.
+type LabelStorage struct {
+	filePath    string
+	mu          sync.Mutex
+	initialized bool
+	open        map[string][]LabelEvent // Eventos em andamento de cada sensor
+}
+
+// NewLabelStorage cria o armazenamento de rótulos no arquivo do dia atual
+func NewLabelStorage(dataDir string) (*LabelStorage, error) {
+	return NewLabelStorageForDate(dataDir, time.Now())
+}
+
+// NewLabelStorageForDate cria o armazenamento de rótulos no arquivo do dia informado
+func NewLabelStorageForDate(dataDir string, date time.Time) (*LabelStorage, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("falha ao criar diretório de dados: %w", err)
+	}
+
+	return &LabelStorage{
+		filePath: DailyLabelsPath(dataDir, date),
+		open:     make(map[string][]LabelEvent),
+	}, nil
+}
+
+// DailyLabelsPath retorna o caminho do arquivo de rótulos do dia informado
+func DailyLabelsPath(dataDir string, date time.Time) string {
+	return filepath.Join(dataDir, fmt.Sprintf("sensor_labels_%s.csv", date.Format("2006-01-02")))
+}
+
+// Initialize inicializa o arquivo CSV de rótulos com o cabeçalho
+func (s *LabelStorage) Initialize() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialize()
+}
+
+// initialize é Initialize com mu já travado
+func (s *LabelStorage) initialize() error {
+	_, err := os.Stat(s.filePath)
+	fileExists := !os.IsNotExist(err)
+
+	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("falha ao abrir arquivo de rótulos: %w", err)
+	}
+	defer file.Close()
+
+	if !fileExists {
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"sensor_id", "sensor_type", "label", "start", "end"}
+		if err := writer.Write(header); err != nil {
+			return fmt.Errorf("falha ao escrever cabeçalho do arquivo de rótulos: %w", err)
+		}
+	}
+
+	s.initialized = true
+	return nil
+}
+
+// StoreReadings acompanha os rótulos das leituras e grava os eventos encerrados
+func (s *LabelStorage) StoreReadings(readings []models.SensorReading) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var closed []LabelEvent
+	for _, reading := range readings {
+		events := s.open[reading.SensorID]
+
+		// Encerrar os eventos cujo rótulo não aparece nesta leitura
+		remaining := events[:0]
+		for _, event := range events {
+			if hasLabel(reading.Labels, event.Label) {
+				event.End = reading.Timestamp
+				remaining = append(remaining, event)
+			} else {
+				closed = append(closed, event)
+			}
+		}
+
+		// Abrir eventos para os rótulos novos
+		for _, label := range reading.Labels {
+			if !hasEvent(remaining, label) {
+				remaining = append(remaining, LabelEvent{
+					SensorID:   reading.SensorID,
+					SensorType: reading.SensorType,
+					Label:      label,
+					Start:      reading.Timestamp,
+					End:        reading.Timestamp,
+				})
+			}
+		}
+
+		if len(remaining) > 0 {
+			s.open[reading.SensorID] = remaining
+		} else {
+			delete(s.open, reading.SensorID)
+		}
+	}
+
+	return s.write(closed)
+}
+
+// Close grava os eventos ainda em andamento, encerrados na última leitura recebida
+func (s *LabelStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.open))
+	for id := range s.open {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var closed []LabelEvent
+	for _, id := range ids {
+		closed = append(closed, s.open[id]...)
+	}
+	s.open = make(map[string][]LabelEvent)
+	return s.write(closed)
+}
+
+// write acrescenta os eventos ao arquivo; deve ser chamado com mu travado
+func (s *LabelStorage) write(events []LabelEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if !s.initialized {
+		if err := s.initialize(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("falha ao abrir arquivo de rótulos: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	for _, event := range events {
+		record := []string{
+			event.SensorID,
+			string(event.SensorType),
+			string(event.Label),
+			event.Start.Format(time.RFC3339Nano),
+			event.End.Format(time.RFC3339Nano),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("falha ao escrever evento no arquivo de rótulos: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasLabel indica se o rótulo está na lista
+func hasLabel(labels []models.AnomalyLabel, label models.AnomalyLabel) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEvent indica se há um evento em andamento com o rótulo
+func hasEvent(events []LabelEvent, label models.AnomalyLabel) bool {
+	for _, event := range events {
+		if event.Label == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// ActuatorType define o tipo de atuador virtual
+type ActuatorType string
+
+const (
+	Heater         ActuatorType = "heater"          // Aquecedor
+	Chiller        ActuatorType = "chiller"         // Resfriador
+	Humidifier     ActuatorType = "humidifier"      // Umidificador
+	Dehumidifier   ActuatorType = "dehumidifier"    // Desumidificador
+	Lights         ActuatorType = "lights"          // Iluminação
+	CO2Valve       ActuatorType = "co2_valve"       // Válvula de CO2
+	IrrigationPump ActuatorType = "irrigation_pump" // Bomba de irrigação
+)
+
+// ValidActuatorType indica se o tipo de atuador é conhecido
+func ValidActuatorType(t ActuatorType) bool {
+	switch t {
+	case Heater, Chiller, Humidifier, Dehumidifier, Lights, CO2Valve, IrrigationPump:
+		return true
+	}
+	return false
+}
+
+// ActuatorEffect descreve o efeito de um atuador sobre um sensor fora de uma sala de cultivo
+type ActuatorEffect struct {
+	SensorID string  `json:"sensor_id"`
+	Rate     float64 `json:"rate"` // Variação por hora com o atuador em potência máxima
+}
+
+// ActuatorConfig contém as configurações de um atuador virtual
+type ActuatorConfig struct {
+	ID      string           `json:"id"`
+	Type    ActuatorType     `json:"type"`
+	Room    string           `json:"room,omitempty"`    // Sala de cultivo afetada pelo atuador
+	Effects []ActuatorEffect `json:"effects,omitempty"` // Efeitos diretos sobre sensores
+}
+
+// ActuatorState representa o estado publicado de um atuador
+type ActuatorState struct {
+	ActuatorID   string       `json:"actuator_id"`
+	ActuatorType ActuatorType `json:"actuator_type"`
+	Value        float64      `json:"value"` // Nível de acionamento (0 = desligado, 1 = potência máxima)
+	Timestamp    time.Time    `json:"timestamp"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "fmt"
+
+// AssetPath localiza um sensor na hierarquia de ativos da planta: local → sala → dispositivo.
+// Níveis vazios são omitidos nos tópicos e caminhos.
+type AssetPath struct {
+	Site   string `json:"site,omitempty"`
+	Room   string `json:"room,omitempty"`
+	Device string `json:"device,omitempty"`
+}
+
+// Segments retorna os níveis preenchidos do caminho, do local ao dispositivo
+func (p AssetPath) Segments() []string {
+	var segments []string
+	for _, level := range []string{p.Site, p.Room, p.Device} {
+		if level != "" {
+			segments = append(segments, level)
+		}
+	}
+	return segments
+}
+
+// Match indica se o caminho atende ao filtro; níveis vazios do filtro aceitam qualquer valor
+func (p AssetPath) Match(filter AssetPath) bool {
+	return (filter.Site == "" || filter.Site == p.Site) &&
+		(filter.Room == "" || filter.Room == p.Room) &&
+		(filter.Device == "" || filter.Device == p.Device)
+}
+
+// Site é um local da planta com as suas salas
+type Site struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Rooms []Room `json:"rooms,omitempty"`
+}
+
+// Room é uma sala de cultivo com os seus dispositivos
+type Room struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name,omitempty"`
+	Devices []Device `json:"devices,omitempty"`
+}
+
+// Device é um controlador ou gateway com os sensores ligados a ele
+type Device struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name,omitempty"`
+	Sensors []string `json:"sensors"` // IDs dos sensores do dispositivo
+}
+
+// AssetPaths retorna o caminho de cada sensor listado na hierarquia de ativos
+func AssetPaths(sites []Site) (map[string]AssetPath, error) {
+	paths := make(map[string]AssetPath)
+	for _, site := range sites {
+		for _, room := range site.Rooms {
+			for _, device := range room.Devices {
+				for _, sensorID := range device.Sensors {
+					if _, ok := paths[sensorID]; ok {
+						return nil, fmt.Errorf("sensor %s listado em mais de um dispositivo", sensorID)
+					}
+					paths[sensorID] = AssetPath{Site: site.ID, Room: room.ID, Device: device.ID}
+				}
+			}
+		}
+	}
+	return paths, nil
+}
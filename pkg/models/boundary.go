@@ -0,0 +1,36 @@
+package models
+
+// BoundaryMode define como um valor gerado fora de min_value/max_value volta à faixa física
+type BoundaryMode string
+
+const (
+	BoundaryClamp         BoundaryMode = "clamp"          // Volta para dentro do limite com ruído (padrão)
+	BoundaryReflect       BoundaryMode = "reflect"        // Reflete o excesso a partir do limite
+	BoundarySoftLimit     BoundaryMode = "soft_limit"     // Comprime o valor perto dos limites (tanh)
+	BoundaryMeanReversion BoundaryMode = "mean_reversion" // Puxa o valor continuamente para um alvo
+	BoundarySaturate      BoundaryMode = "saturate"       // Fica preso no limite, como um sensor no fundo de escala
+	BoundaryWrap          BoundaryMode = "wrap"           // Reentra pelo limite oposto (ex.: ângulos)
+)
+
+// ValidBoundaryMode indica se o modo de limite é conhecido
+func ValidBoundaryMode(mode BoundaryMode) bool {
+	switch mode {
+	case BoundaryClamp, BoundaryReflect, BoundarySoftLimit, BoundaryMeanReversion, BoundarySaturate, BoundaryWrap:
+		return true
+	}
+	return false
+}
+
+// BoundaryConfig configura o tratamento dos limites da faixa física do sensor
+type BoundaryConfig struct {
+	Mode   BoundaryMode `json:"mode"`
+	Target *float64     `json:"target,omitempty"` // Alvo da reversão à média (padrão: centro da faixa de operação ou física)
+	Rate   float64      `json:"rate,omitempty"`   // Velocidade da reversão à média (1/h, padrão: 1)
+	Knee   float64      `json:"knee,omitempty"`   // Fração da faixa comprimida junto a cada limite no soft_limit (padrão: 0.1)
+}
+
+// ValueRange é um intervalo de valores de um sensor
+type ValueRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration é uma duração que aceita em JSON tanto nanossegundos (número)
+// quanto texto no formato de time.ParseDuration (ex.: "10m", "1h30m")
+type Duration time.Duration
+
+// MarshalJSON serializa a duração como texto (ex.: "10m0s")
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON aceita um número em nanossegundos ou um texto de duração
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+	case float64:
+		*d = Duration(time.Duration(value))
+	case string:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("duração inválida %q: %w", value, err)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("duração inválida: %s", string(data))
+	}
+	return nil
+}
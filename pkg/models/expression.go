@@ -0,0 +1,68 @@
+package models
+
+import "unicode"
+
+// ExprToken é um símbolo de uma expressão de sensor derivado
+type ExprToken struct {
+	Text  string // Texto do símbolo (vazio no fim da expressão)
+	Pos   int    // Posição do início do símbolo na expressão
+	Ident bool   // O símbolo é um identificador (sensor ou função)
+}
+
+// NextExprToken lê o símbolo que começa em pos, ignorando espaços, e retorna a posição
+// seguinte. Identificadores podem conter letras, dígitos, "_", "." e hífens seguidos de
+// letra ou dígito (ex.: room001-temp001); números aceitam notação científica (ex.: 1e-3).
+func NextExprToken(expr string, pos int) (ExprToken, int) {
+	for pos < len(expr) && (expr[pos] == ' ' || expr[pos] == '\t') {
+		pos++
+	}
+	if pos >= len(expr) {
+		return ExprToken{Pos: pos}, pos
+	}
+
+	start := pos
+	ident := false
+	c := rune(expr[pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for pos < len(expr) && (unicode.IsDigit(rune(expr[pos])) || expr[pos] == '.') {
+			pos++
+		}
+		// Notação científica (ex.: 1e-3)
+		if pos < len(expr) && (expr[pos] == 'e' || expr[pos] == 'E') {
+			end := pos + 1
+			if end < len(expr) && (expr[end] == '+' || expr[end] == '-') {
+				end++
+			}
+			if end < len(expr) && unicode.IsDigit(rune(expr[end])) {
+				pos = end
+				for pos < len(expr) && unicode.IsDigit(rune(expr[pos])) {
+					pos++
+				}
+			}
+		}
+	case unicode.IsLetter(c) || c == '_':
+		ident = true
+		for pos < len(expr) {
+			ch := rune(expr[pos])
+			if unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '.' {
+				pos++
+				continue
+			}
+			// Hífen faz parte do identificador quando seguido de letra ou dígito
+			if ch == '-' && pos+1 < len(expr) && isIdentRune(rune(expr[pos+1])) {
+				pos++
+				continue
+			}
+			break
+		}
+	default:
+		pos++
+	}
+	return ExprToken{Text: expr[start:pos], Pos: start, Ident: ident}, pos
+}
+
+// isIdentRune indica se o caractere pode continuar um identificador
+func isIdentRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_'
+}
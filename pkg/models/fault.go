@@ -0,0 +1,36 @@
+package models
+
+// FaultType define o tipo de falha injetada em um sensor
+type FaultType string
+
+const (
+	FaultStuck      FaultType = "stuck"      // Valor travado
+	FaultSpike      FaultType = "spike"      // Picos e outliers esporádicos
+	FaultDropout    FaultType = "dropout"    // Leituras ausentes
+	FaultDrift      FaultType = "drift"      // Deriva lenta de calibração
+	FaultOffset     FaultType = "offset"     // Salto de offset
+	FaultSaturation FaultType = "saturation" // Saturação fora da faixa
+	FaultNaN        FaultType = "nan"        // Leituras NaN
+	FaultInf        FaultType = "inf"        // Leituras infinitas
+	FaultFlatline   FaultType = "flatline"   // Sinal plano com ruído mínimo
+)
+
+// FaultConfig descreve uma falha agendada para um sensor
+type FaultConfig struct {
+	Type        FaultType `json:"type"`
+	Start       Duration  `json:"start,omitempty"`       // Atraso até a falha começar
+	Duration    Duration  `json:"duration,omitempty"`    // Duração da falha (0 = indefinida)
+	Value       *float64  `json:"value,omitempty"`       // Valor travado/saturado (stuck, flatline, saturation)
+	Magnitude   float64   `json:"magnitude,omitempty"`   // Amplitude do pico, offset, deriva por hora ou ruído do flatline
+	Probability float64   `json:"probability,omitempty"` // Probabilidade por leitura (spike, dropout, nan, inf)
+}
+
+// ValidFaultType indica se o tipo de falha é conhecido
+func ValidFaultType(t FaultType) bool {
+	switch t {
+	case FaultStuck, FaultSpike, FaultDropout, FaultDrift, FaultOffset,
+		FaultSaturation, FaultNaN, FaultInf, FaultFlatline:
+		return true
+	}
+	return false
+}
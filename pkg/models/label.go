@@ -0,0 +1,18 @@
+package models
+
+// AnomalyLabel identifica um evento anômalo gerado pelo simulador em uma leitura
+type AnomalyLabel string
+
+const (
+	LabelRain      AnomalyLabel = "rain"       // Pico de umidade (chuva/irrigação)
+	LabelCloud     AnomalyLabel = "cloud"      // Queda de luminosidade pela passagem de nuvens
+	LabelClampMin  AnomalyLabel = "clamp_min"  // Valor gerado abaixo de MinValue, trazido para a faixa
+	LabelClampMax  AnomalyLabel = "clamp_max"  // Valor gerado acima de MaxValue, trazido para a faixa
+	LabelAlarmLow  AnomalyLabel = "alarm_low"  // Leitura abaixo da faixa normal de operação
+	LabelAlarmHigh AnomalyLabel = "alarm_high" // Leitura acima da faixa normal de operação
+)
+
+// FaultLabel retorna o rótulo de uma falha injetada (ex.: "fault_stuck")
+func FaultLabel(t FaultType) AnomalyLabel {
+	return AnomalyLabel("fault_" + string(t))
+}
@@ -0,0 +1,14 @@
+package models
+
+// MeasurementConfig descreve a cadeia de medição entre a grandeza física e o valor
+// reportado pelo sensor. Campos zerados desativam a etapa correspondente.
+type MeasurementConfig struct {
+	TimeConstant      Duration `json:"time_constant,omitempty"`       // Constante de tempo da resposta de primeira ordem
+	Hysteresis        float64  `json:"hysteresis,omitempty"`          // Largura da histerese (unidade do sensor)
+	AccuracyReading   float64  `json:"accuracy_reading,omitempty"`    // Exatidão em ± % da leitura
+	AccuracyFullScale float64  `json:"accuracy_full_scale,omitempty"` // Exatidão em ± % do fundo de escala
+	ADCBits           int      `json:"adc_bits,omitempty"`            // Resolução do conversor A/D em bits
+	ADCMin            float64  `json:"adc_min,omitempty"`             // Início da faixa do conversor (padrão: min_value)
+	ADCMax            float64  `json:"adc_max,omitempty"`             // Fim da faixa do conversor (padrão: max_value)
+	Resolution        float64  `json:"resolution,omitempty"`          // Passo do valor reportado (ex.: 0.1)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SensorMetadata reúne os dados de inventário de um sensor, sem efeito na simulação
+type SensorMetadata struct {
+	Name        string    `json:"name,omitempty"`        // Nome legível (ex.: "Temperatura da estufa 1")
+	Description string    `json:"description,omitempty"` // Descrição livre
+	Tags        []string  `json:"tags,omitempty"`        // Etiquetas livres (ex.: "critico", "linha-a")
+	Location    *Location `json:"location,omitempty"`    // Posição física do sensor
+
+	Manufacturer string `json:"manufacturer,omitempty"` // Fabricante
+	Model        string `json:"model,omitempty"`        // Modelo
+	Serial       string `json:"serial,omitempty"`       // Número de série
+	Firmware     string `json:"firmware,omitempty"`     // Versão do firmware
+
+	CalibrationDate *Date `json:"calibration_date,omitempty"` // Data da última calibração
+	CalibrationDue  *Date `json:"calibration_due,omitempty"`  // Data da próxima calibração
+}
+
+// Location é a posição física de um sensor: a sala e as coordenadas dentro dela (em metros)
+type Location struct {
+	Room string  `json:"room,omitempty"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Z    float64 `json:"z"`
+}
+
+// HasTag indica se o sensor tem a etiqueta informada
+func (m SensorMetadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Date é uma data sem horário, serializada em JSON como "AAAA-MM-DD"
+type Date struct {
+	time.Time
+}
+
+// MarshalJSON serializa a data no formato AAAA-MM-DD
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+// UnmarshalJSON aceita uma data AAAA-MM-DD ou um instante RFC3339
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	if t, err := time.Parse(time.DateOnly, text); err == nil {
+		d.Time = t
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return fmt.Errorf("data inválida %q (use AAAA-MM-DD)", text)
+	}
+	d.Time = t
+	return nil
+}
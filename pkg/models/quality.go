@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Quality é o código de qualidade de uma leitura, com os mesmos valores do StatusCode
+// do OPC-UA: os dois bits mais altos indicam a severidade (Good, Uncertain ou Bad) e os
+// bits seguintes o subestado
+type Quality uint32
+
+const (
+	QualityGood Quality = 0x00000000 // Leitura confiável
+
+	QualityUncertainNoCommunicationLastUsableValue Quality = 0x408F0000 // Sem comunicação; último valor conhecido
+	QualityUncertainLastUsableValue                Quality = 0x40900000 // Valor travado no último valor utilizável
+	QualityUncertainSensorNotAccurate              Quality = 0x40930000 // Sensor fora de calibração ou com ruído anômalo
+	QualityUncertainEngineeringUnitsExceeded       Quality = 0x40940000 // Valor limitado à faixa física do sensor
+
+	QualityBadCommunicationError Quality = 0x80050000 // Falha de comunicação com o sensor
+	QualityBadOutOfRange         Quality = 0x803C0000 // Valor fora da faixa física do sensor
+	QualityBadSensorFailure      Quality = 0x808C0000 // Falha do sensor (NaN, infinito)
+)
+
+// Máscaras de severidade do código de qualidade
+const (
+	qualitySeverityMask Quality = 0xC0000000
+	qualityUncertain    Quality = 0x40000000
+	qualityBad          Quality = 0x80000000
+)
+
+var qualityNames = map[Quality]string{
+	QualityGood: "Good",
+	QualityUncertainNoCommunicationLastUsableValue: "UncertainNoCommunicationLastUsableValue",
+	QualityUncertainLastUsableValue:                "UncertainLastUsableValue",
+	QualityUncertainSensorNotAccurate:              "UncertainSensorNotAccurate",
+	QualityUncertainEngineeringUnitsExceeded:       "UncertainEngineeringUnitsExceeded",
+	QualityBadCommunicationError:                   "BadCommunicationError",
+	QualityBadOutOfRange:                           "BadOutOfRange",
+	QualityBadSensorFailure:                        "BadSensorFailure",
+}
+
+// IsGood indica se a leitura tem qualidade boa
+func (q Quality) IsGood() bool {
+	return q&qualitySeverityMask == 0
+}
+
+// IsUncertain indica se a leitura tem qualidade incerta
+func (q Quality) IsUncertain() bool {
+	return q&qualitySeverityMask == qualityUncertain
+}
+
+// IsBad indica se a leitura tem qualidade ruim
+func (q Quality) IsBad() bool {
+	return q&qualityBad != 0
+}
+
+// Severity retorna a severidade da qualidade: "good", "uncertain" ou "bad"
+func (q Quality) Severity() string {
+	switch {
+	case q.IsBad():
+		return "bad"
+	case q.IsUncertain():
+		return "uncertain"
+	}
+	return "good"
+}
+
+// Worst retorna a pior das duas qualidades; em caso de empate, mantém q
+func (q Quality) Worst(other Quality) Quality {
+	if severityRank(other) > severityRank(q) {
+		return other
+	}
+	return q
+}
+
+// severityRank ordena as severidades: Good < Uncertain < Bad
+func severityRank(q Quality) int {
+	switch {
+	case q.IsBad():
+		return 2
+	case q.IsUncertain():
+		return 1
+	}
+	return 0
+}
+
+// String retorna o nome do código no OPC-UA (ex.: "BadSensorFailure") ou o código em
+// hexadecimal, se não for conhecido
+func (q Quality) String() string {
+	if name, ok := qualityNames[q]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08X", uint32(q))
+}
+
+// MarshalText serializa a qualidade pelo nome
+func (q Quality) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText aceita o nome do código ou o seu valor numérico (decimal ou 0x...)
+func (q *Quality) UnmarshalText(text []byte) error {
+	parsed, err := ParseQuality(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// ParseQuality interpreta um código de qualidade pelo nome ou valor numérico; vazio é Good
+func ParseQuality(value string) (Quality, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return QualityGood, nil
+	}
+	for q, name := range qualityNames {
+		if strings.EqualFold(name, value) {
+			return q, nil
+		}
+	}
+	code, err := strconv.ParseUint(value, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("código de qualidade inválido %q", value)
+	}
+	return Quality(code), nil
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"math"
+	"strconv"
 	"time"
 )
 
@@ -12,6 +15,21 @@ const (
 	Humidity    SensorType = "humidity"    // Umidade (%)
 	Temperature SensorType = "temperature" // Temperatura (°C)
 	Pressure    SensorType = "pressure"    // Pressão (hPa)
+
+	// Tipos específicos de cultivo
+	CO2               SensorType = "co2"                // Concentração de CO2 (ppm)
+	PPFD              SensorType = "ppfd"               // Densidade de fluxo de fótons fotossintéticos (µmol/m²/s)
+	SubstrateMoisture SensorType = "substrate_moisture" // Umidade do substrato (%)
+	PH                SensorType = "ph"                 // pH da solução nutritiva
+	EC                SensorType = "ec"                 // Condutividade elétrica da solução (mS/cm)
+	WaterTemperature  SensorType = "water_temperature"  // Temperatura da solução nutritiva (°C)
+	LeafTemperature   SensorType = "leaf_temperature"   // Temperatura da folha (°C)
+
+	// Grandezas normalmente calculadas por sensores derivados
+	VPD       SensorType = "vpd"        // Déficit de pressão de vapor (kPa)
+	DewPoint  SensorType = "dew_point"  // Ponto de orvalho (°C)
+	HeatIndex SensorType = "heat_index" // Índice de calor (°C)
+	DLI       SensorType = "dli"        // Integral diária de luz (mol/m²/d)
 )
 
 // SensorReading representa uma leitura de um sensor
@@ -21,6 +39,74 @@ type SensorReading struct {
 	Value      float64    `json:"value"`
 	Unit       string     `json:"unit"`
 	Timestamp  time.Time  `json:"timestamp"`
+
+	// Qualidade da leitura, com os códigos de StatusCode do OPC-UA
+	Quality Quality `json:"quality"`
+
+	// Falhas ativas no momento da leitura (verdade de referência)
+	Faults []FaultType `json:"faults,omitempty"`
+
+	// Eventos anômalos presentes na leitura, incluindo as falhas ativas (verdade de referência)
+	Labels []AnomalyLabel `json:"labels,omitempty"`
+
+	// Amostra descartada por falha (dropout): o registro leva apenas os rótulos, sem valor
+	Dropped bool `json:"dropped,omitempty"`
+
+	// Posição do sensor na hierarquia de ativos
+	Asset *AssetPath `json:"asset,omitempty"`
+}
+
+// Path retorna o caminho do sensor da leitura na hierarquia de ativos (vazio se não informado)
+func (r SensorReading) Path() AssetPath {
+	if r.Asset == nil {
+		return AssetPath{}
+	}
+	return *r.Asset
+}
+
+// sensorReadingJSON evita recursão ao serializar SensorReading
+type sensorReadingJSON SensorReading
+
+// MarshalJSON serializa a leitura; valores NaN/Inf são escritos como texto
+// ("NaN", "+Inf", "-Inf"), já que JSON não os representa como número
+func (r SensorReading) MarshalJSON() ([]byte, error) {
+	if !math.IsNaN(r.Value) && !math.IsInf(r.Value, 0) {
+		return json.Marshal(sensorReadingJSON(r))
+	}
+
+	return json.Marshal(struct {
+		sensorReadingJSON
+		Value string `json:"value"`
+	}{
+		sensorReadingJSON: sensorReadingJSON(r),
+		Value:             strconv.FormatFloat(r.Value, 'f', -1, 64),
+	})
+}
+
+// UnmarshalJSON desserializa a leitura aceitando valores numéricos ou textuais
+func (r *SensorReading) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		sensorReadingJSON
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*r = SensorReading(aux.sensorReadingJSON)
+
+	if len(aux.Value) == 0 {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(aux.Value, &text); err == nil {
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		r.Value = value
+		return nil
+	}
+	return json.Unmarshal(aux.Value, &r.Value)
 }
 
 // SensorConfig contém as configurações de um sensor
@@ -31,15 +117,76 @@ type SensorConfig struct {
 	MaxValue       float64    `json:"max_value"`
 	NoiseAmplitude float64    `json:"noise_amplitude"` // Amplitude do ruído para simulação
 	Unit           string     `json:"unit"`
+
+	// Dados de inventário: nome, etiquetas, localização, fabricante, série e calibração
+	SensorMetadata
+
+	// Posição do sensor na hierarquia de ativos (local, sala e dispositivo). Pode ser
+	// omitida quando o sensor é listado em "assets" na configuração.
+	Asset *AssetPath `json:"asset,omitempty"`
+
+	// Gerador de sinal usado na simulação (opcional, o padrão depende do tipo)
+	Generator *GeneratorConfig `json:"generator,omitempty"`
+
+	// Falhas agendadas a partir do início da simulação
+	Faults []FaultConfig `json:"faults,omitempty"`
+
+	// Amostragem própria do sensor. Sem intervalo, o sensor é amostrado a cada ciclo
+	// da simulação; com intervalo, as amostras ocorrem em start+offset+k*intervalo,
+	// deslocadas aleatoriamente em até ±jitter.
+	SampleInterval Duration `json:"sample_interval,omitempty"`
+	SampleOffset   Duration `json:"sample_offset,omitempty"`
+	SampleJitter   Duration `json:"sample_jitter,omitempty"`
+
+	// Cadeia de medição aplicada ao valor simulado (opcional)
+	Measurement *MeasurementConfig `json:"measurement,omitempty"`
+
+	// Tratamento de valores gerados fora de min_value/max_value, a faixa física do sensor
+	// (padrão: clamp)
+	Boundary *BoundaryConfig `json:"boundary,omitempty"`
+
+	// Faixa normal de operação, dentro da faixa física. Leituras fora dela recebem os
+	// rótulos alarm_low e alarm_high, para testar alarmes.
+	OperatingRange *ValueRange `json:"operating_range,omitempty"`
+
+	// Expressão de um sensor derivado sobre outros sensores, ex.: "vpd(temp001, hum001)".
+	// Sensores derivados não usam gerador de sinal.
+	Expression string `json:"expression,omitempty"`
+}
+
+// Path retorna o caminho do sensor na hierarquia de ativos (vazio se não informado)
+func (c SensorConfig) Path() AssetPath {
+	if c.Asset == nil {
+		return AssetPath{}
+	}
+	return *c.Asset
+}
+
+// Derived indica se o sensor é calculado a partir de outros sensores
+func (c SensorConfig) Derived() bool {
+	return c.Expression != ""
+}
+
+// GeneratorConfig seleciona um gerador de sinal pelo nome e define seus parâmetros
+type GeneratorConfig struct {
+	Name   string             `json:"name"`
+	Params map[string]float64 `json:"params,omitempty"`
+	Room   string             `json:"room,omitempty"` // Sala de cultivo usada pelo gerador "grow_room"
 }
 
 // NewSensorReading cria uma nova leitura de sensor
 func NewSensorReading(config SensorConfig, value float64) SensorReading {
+	return NewSensorReadingAt(config, value, time.Now())
+}
+
+// NewSensorReadingAt cria uma nova leitura de sensor com o timestamp informado
+func NewSensorReadingAt(config SensorConfig, value float64, timestamp time.Time) SensorReading {
 	return SensorReading{
 		SensorID:   config.ID,
 		SensorType: config.Type,
 		Value:      value,
 		Unit:       config.Unit,
-		Timestamp:  time.Now(),
+		Timestamp:  timestamp,
+		Asset:      config.Asset,
 	}
 }
@@ -0,0 +1,62 @@
+package models
+
+// SensorTypeInfo reúne o título, a unidade e a faixa típica de um tipo de sensor
+type SensorTypeInfo struct {
+	Title          string  `json:"title"`
+	Unit           string  `json:"unit"`
+	MinValue       float64 `json:"min_value"`
+	MaxValue       float64 `json:"max_value"`
+	NoiseAmplitude float64 `json:"noise_amplitude"`
+}
+
+// sensorTypes contém os valores típicos de cada tipo conhecido em uma sala de cultivo
+var sensorTypes = map[SensorType]SensorTypeInfo{
+	Temperature:       {Title: "Temperatura", Unit: "°C", MinValue: 18, MaxValue: 30, NoiseAmplitude: 0.3},
+	Humidity:          {Title: "Umidade", Unit: "%", MinValue: 40, MaxValue: 75, NoiseAmplitude: 1},
+	Light:             {Title: "Luminosidade", Unit: "lux", MinValue: 0, MaxValue: 1000, NoiseAmplitude: 10},
+	Pressure:          {Title: "Pressão", Unit: "hPa", MinValue: 990, MaxValue: 1020, NoiseAmplitude: 0.5},
+	CO2:               {Title: "CO2", Unit: "ppm", MinValue: 300, MaxValue: 2000, NoiseAmplitude: 10},
+	PPFD:              {Title: "PPFD", Unit: "µmol/m²/s", MinValue: 0, MaxValue: 1500, NoiseAmplitude: 5},
+	SubstrateMoisture: {Title: "Umidade do Substrato", Unit: "%", MinValue: 0, MaxValue: 100, NoiseAmplitude: 0.3},
+	PH:                {Title: "pH da Solução", Unit: "pH", MinValue: 4.5, MaxValue: 8, NoiseAmplitude: 0.02},
+	EC:                {Title: "Condutividade (EC)", Unit: "mS/cm", MinValue: 0, MaxValue: 4, NoiseAmplitude: 0.02},
+	WaterTemperature:  {Title: "Temperatura da Água", Unit: "°C", MinValue: 10, MaxValue: 30, NoiseAmplitude: 0.1},
+	LeafTemperature:   {Title: "Temperatura da Folha", Unit: "°C", MinValue: 15, MaxValue: 35, NoiseAmplitude: 0.2},
+	VPD:               {Title: "VPD", Unit: "kPa", MinValue: 0, MaxValue: 3, NoiseAmplitude: 0.02},
+	DewPoint:          {Title: "Ponto de Orvalho", Unit: "°C", MinValue: 0, MaxValue: 30, NoiseAmplitude: 0.2},
+	HeatIndex:         {Title: "Índice de Calor", Unit: "°C", MinValue: 15, MaxValue: 45, NoiseAmplitude: 0.3},
+	DLI:               {Title: "DLI", Unit: "mol/m²/d", MinValue: 0, MaxValue: 65, NoiseAmplitude: 0.1},
+}
+
+// Info retorna os valores típicos do tipo de sensor e se o tipo é conhecido
+func (t SensorType) Info() (SensorTypeInfo, bool) {
+	info, ok := sensorTypes[t]
+	return info, ok
+}
+
+// Title retorna o nome do tipo de sensor para exibição
+func (t SensorType) Title() string {
+	if info, ok := sensorTypes[t]; ok {
+		return info.Title
+	}
+	return string(t)
+}
+
+// WithDefaults preenche a unidade e, quando a faixa não foi informada, a faixa e o
+// ruído com os valores típicos do tipo de sensor
+func (c SensorConfig) WithDefaults() SensorConfig {
+	info, ok := c.Type.Info()
+	if !ok {
+		return c
+	}
+	if c.Unit == "" {
+		c.Unit = info.Unit
+	}
+	if c.MinValue == 0 && c.MaxValue == 0 {
+		c.MinValue, c.MaxValue = info.MinValue, info.MaxValue
+		if c.NoiseAmplitude == 0 {
+			c.NoiseAmplitude = info.NoiseAmplitude
+		}
+	}
+	return c
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SensorTemplate gera um grupo repetido de sensores, como "200 salas × 8 sensores",
+// sem precisar listar cada SensorConfig
+type SensorTemplate struct {
+	Prefix  string         `json:"prefix"`  // Prefixo de cada grupo (ex.: "room")
+	Count   int            `json:"count"`   // Número de grupos (ex.: salas ou dispositivos)
+	Sensors []SensorConfig `json:"sensors"` // Sensores repetidos em cada grupo
+
+	// Nível da hierarquia de ativos representado por cada grupo ("site", "room" ou
+	// "device"); o nome do grupo é usado nesse nível do caminho de cada sensor
+	Level string `json:"level,omitempty"`
+}
+
+// Expand gera os sensores do template. O ID de cada sensor recebe o prefixo e o número
+// do grupo, por exemplo "room007-temp001".
+func (t SensorTemplate) Expand() []SensorConfig {
+	width := len(strconv.Itoa(t.Count))
+	if width < 3 {
+		width = 3
+	}
+
+	// Sensores derivados do template referenciam os sensores do mesmo grupo
+	siblings := make(map[string]bool, len(t.Sensors))
+	for _, sensor := range t.Sensors {
+		siblings[sensor.ID] = true
+	}
+
+	sensors := make([]SensorConfig, 0, t.Count*len(t.Sensors))
+	for i := 1; i <= t.Count; i++ {
+		group := fmt.Sprintf("%s%0*d", t.Prefix, width, i)
+		for _, sensor := range t.Sensors {
+			sensor.ID = group + "-" + sensor.ID
+			if sensor.Expression != "" {
+				sensor.Expression = renameSensors(sensor.Expression, func(id string) string {
+					if siblings[id] {
+						return group + "-" + id
+					}
+					return id
+				})
+			}
+			sensor.Asset = t.groupAsset(sensor.Asset, group)
+			sensors = append(sensors, sensor)
+		}
+	}
+	return sensors
+}
+
+// Validate verifica o nível da hierarquia de ativos do template
+func (t SensorTemplate) Validate() error {
+	switch t.Level {
+	case "", "site", "room", "device":
+		return nil
+	}
+	return fmt.Errorf("nível %q inválido no template %q (use site, room ou device)", t.Level, t.Prefix)
+}
+
+// renameSensors reescreve os sensores referenciados em uma expressão de sensor derivado.
+// Nomes de funções (seguidos de "("), números, operadores e espaços são mantidos.
+func renameSensors(expr string, rename func(id string) string) string {
+	var b strings.Builder
+	last := 0
+	for pos := 0; pos < len(expr); {
+		var tok ExprToken
+		tok, pos = NextExprToken(expr, pos)
+		if !tok.Ident {
+			continue
+		}
+		next, _ := NextExprToken(expr, pos)
+		if next.Text == "(" {
+			continue
+		}
+		b.WriteString(expr[last:tok.Pos])
+		b.WriteString(rename(tok.Text))
+		last = pos
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
+
+// groupAsset retorna o caminho do sensor com o nível do template preenchido pelo grupo
+func (t SensorTemplate) groupAsset(asset *AssetPath, group string) *AssetPath {
+	var path AssetPath
+	if asset != nil {
+		path = *asset
+	}
+	switch t.Level {
+	case "site":
+		path.Site = group
+	case "room":
+		path.Room = group
+	case "device":
+		path.Device = group
+	default:
+		return asset
+	}
+	return &path
+}
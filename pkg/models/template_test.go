@@ -0,0 +1,44 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSensorTemplateExpandRemapsDerived(t *testing.T) {
+	template := SensorTemplate{
+		Prefix: "room",
+		Count:  2,
+		Level:  "room",
+		Sensors: []SensorConfig{
+			{ID: "temp", Type: Temperature},
+			{ID: "hum", Type: Humidity},
+			{ID: "vpd", Type: VPD, Expression: "vpd(temp, hum) * 1e-3 + outside-temp"},
+		},
+	}
+
+	var expressions []string
+	for _, sensor := range template.Expand() {
+		if sensor.Expression != "" {
+			expressions = append(expressions, sensor.Expression)
+		}
+	}
+	want := []string{
+		"vpd(room001-temp, room001-hum) * 1e-3 + outside-temp",
+		"vpd(room002-temp, room002-hum) * 1e-3 + outside-temp",
+	}
+	if !reflect.DeepEqual(expressions, want) {
+		t.Fatalf("expressões expandidas = %q, esperado %q", expressions, want)
+	}
+}
+
+func TestSensorTemplateValidate(t *testing.T) {
+	for _, level := range []string{"", "site", "room", "device"} {
+		if err := (SensorTemplate{Level: level}).Validate(); err != nil {
+			t.Errorf("nível %q rejeitado: %v", level, err)
+		}
+	}
+	if err := (SensorTemplate{Level: "floor"}).Validate(); err == nil {
+		t.Error("nível inválido aceito")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"go-sensors-simulator/pkg/models"
@@ -28,6 +31,9 @@ type MQTTClient struct {
 	client    mqtt.Client
 	config    MQTTConfig
 	connected bool
+
+	mu             sync.Mutex
+	commandHandler func(actuatorID string, value float64) // Comandos de atuadores recebidos
 }
 
 // defaultHandler é a função de callback padrão para mensagens MQTT
@@ -46,6 +52,10 @@ func NewMQTTClient(config MQTTConfig) (*MQTTClient, error) {
 		opts.SetPassword(config.Password)
 	}
 
+	m := &MQTTClient{
+		config: config,
+	}
+
 	opts.SetDefaultPublishHandler(defaultHandler)
 	opts.SetAutoReconnect(true)
 	opts.SetMaxReconnectInterval(5 * time.Minute)
@@ -55,18 +65,20 @@ func NewMQTTClient(config MQTTConfig) (*MQTTClient, error) {
 	// Definir funções de callback
 	opts.SetOnConnectHandler(func(client mqtt.Client) {
 		log.Println("Conectado ao broker MQTT")
+
+		// Refazer a assinatura de comandos após reconexões
+		if err := m.subscribeCommands(); err != nil {
+			log.Printf("Erro ao assinar comandos de atuadores: %v", err)
+		}
 	})
 
 	opts.SetConnectionLostHandler(func(client mqtt.Client, err error) {
 		log.Printf("Conexão perdida com o broker MQTT: %v\n", err)
 	})
 
-	client := mqtt.NewClient(opts)
+	m.client = mqtt.NewClient(opts)
 
-	return &MQTTClient{
-		client: client,
-		config: config,
-	}, nil
+	return m, nil
 }
 
 // Connect estabelece conexão com o broker MQTT
@@ -92,8 +104,8 @@ func (m *MQTTClient) PublishReading(reading models.SensorReading) error {
 		return fmt.Errorf("cliente MQTT não está conectado")
 	}
 
-	// Criar tópico baseado no tipo de sensor e ID
-	topic := fmt.Sprintf("%s/%s/%s", m.config.TopicBase, reading.SensorType, reading.SensorID)
+	// Criar tópico baseado na hierarquia de ativos ou no tipo de sensor e ID
+	topic := m.sensorTopic(reading.SensorType, reading.SensorID, reading.Path())
 
 	// Converter leitura para JSON
 	payload, err := json.Marshal(reading)
@@ -110,6 +122,80 @@ func (m *MQTTClient) PublishReading(reading models.SensorReading) error {
 	return nil
 }
 
+// sensorTopic monta o tópico das leituras de um sensor: <base>/<local>/<sala>/<dispositivo>/<id>
+// para sensores na hierarquia de ativos ou <base>/<tipo>/<id> para os demais
+func (m *MQTTClient) sensorTopic(sensorType models.SensorType, sensorID string, asset models.AssetPath) string {
+	if segments := asset.Segments(); len(segments) > 0 {
+		return fmt.Sprintf("%s/%s/%s", m.config.TopicBase, strings.Join(segments, "/"), sensorID)
+	}
+	return fmt.Sprintf("%s/%s/%s", m.config.TopicBase, sensorType, sensorID)
+}
+
+// ClearSensorTopic limpa as mensagens retidas nos tópicos de um sensor removido ou alterado em
+// execução, para que novos assinantes não recebam a última leitura nem os metadados de um
+// sensor que não existe
+func (m *MQTTClient) ClearSensorTopic(sensor models.SensorConfig) error {
+	if !m.connected {
+		return fmt.Errorf("cliente MQTT não está conectado")
+	}
+
+	// Um payload vazio retido remove a mensagem retida do broker
+	topic := m.sensorTopic(sensor.Type, sensor.ID, sensor.Path())
+	topics := []string{topic + "/metadata"}
+	if m.config.Retained {
+		topics = append(topics, topic)
+	}
+	for _, t := range topics {
+		token := m.client.Publish(t, m.config.QoS, true, []byte{})
+		if token.Wait() && token.Error() != nil {
+			return fmt.Errorf("falha ao limpar tópico do sensor %s: %w", sensor.ID, token.Error())
+		}
+	}
+	return nil
+}
+
+// sensorMetadata é a mensagem publicada no tópico de metadados de um sensor
+type sensorMetadata struct {
+	SensorID   string            `json:"sensor_id"`
+	SensorType models.SensorType `json:"sensor_type"`
+	Unit       string            `json:"unit"`
+	MinValue   float64           `json:"min_value"`
+	MaxValue   float64           `json:"max_value"`
+	Asset      *models.AssetPath `json:"asset,omitempty"`
+	models.SensorMetadata
+}
+
+// PublishSensorMetadata publica os metadados de cada sensor em <tópico do sensor>/metadata.
+// As mensagens são sempre retidas, para que sistemas de inventário as recebam ao assinar.
+func (m *MQTTClient) PublishSensorMetadata(sensors []models.SensorConfig) error {
+	if !m.connected {
+		return fmt.Errorf("cliente MQTT não está conectado")
+	}
+
+	for _, sensor := range sensors {
+		payload, err := json.Marshal(sensorMetadata{
+			SensorID:       sensor.ID,
+			SensorType:     sensor.Type,
+			Unit:           sensor.Unit,
+			MinValue:       sensor.MinValue,
+			MaxValue:       sensor.MaxValue,
+			Asset:          sensor.Asset,
+			SensorMetadata: sensor.SensorMetadata,
+		})
+		if err != nil {
+			return fmt.Errorf("falha ao serializar metadados do sensor para JSON: %w", err)
+		}
+
+		topic := m.sensorTopic(sensor.Type, sensor.ID, sensor.Path()) + "/metadata"
+		token := m.client.Publish(topic, m.config.QoS, true, payload)
+		if token.Wait() && token.Error() != nil {
+			return fmt.Errorf("falha ao publicar metadados do sensor %s: %w", sensor.ID, token.Error())
+		}
+	}
+
+	return nil
+}
+
 // PublishReadings publica várias leituras de sensores
 func (m *MQTTClient) PublishReadings(readings []models.SensorReading) error {
 	if !m.connected {
@@ -124,3 +210,94 @@ func (m *MQTTClient) PublishReadings(readings []models.SensorReading) error {
 
 	return nil
 }
+
+// PublishActuatorStates publica o estado dos atuadores em <base>/actuators/<tipo>/<id>
+func (m *MQTTClient) PublishActuatorStates(states []models.ActuatorState) error {
+	if !m.connected {
+		return fmt.Errorf("cliente MQTT não está conectado")
+	}
+
+	for _, state := range states {
+		topic := fmt.Sprintf("%s/actuators/%s/%s", m.config.TopicBase, state.ActuatorType, state.ActuatorID)
+
+		payload, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("falha ao serializar estado do atuador para JSON: %w", err)
+		}
+
+		token := m.client.Publish(topic, m.config.QoS, m.config.Retained, payload)
+		if token.Wait() && token.Error() != nil {
+			return fmt.Errorf("falha ao publicar estado do atuador: %w", token.Error())
+		}
+	}
+
+	return nil
+}
+
+// SubscribeActuatorCommands assina os tópicos de comando <base>/actuators/<tipo>/<id>/set.
+// O payload pode ser um número (0 a 1), "on"/"off" ou um JSON {"value": x}.
+func (m *MQTTClient) SubscribeActuatorCommands(handler func(actuatorID string, value float64)) error {
+	m.mu.Lock()
+	m.commandHandler = handler
+	m.mu.Unlock()
+
+	if !m.connected {
+		// A assinatura será feita ao conectar
+		return nil
+	}
+	return m.subscribeCommands()
+}
+
+// subscribeCommands assina os tópicos de comando, se houver um handler registrado
+func (m *MQTTClient) subscribeCommands() error {
+	m.mu.Lock()
+	handler := m.commandHandler
+	m.mu.Unlock()
+	if handler == nil {
+		return nil
+	}
+
+	topic := fmt.Sprintf("%s/actuators/+/+/set", m.config.TopicBase)
+	token := m.client.Subscribe(topic, m.config.QoS, func(client mqtt.Client, msg mqtt.Message) {
+		// Tópico: <base>/actuators/<tipo>/<id>/set
+		parts := strings.Split(msg.Topic(), "/")
+		if len(parts) < 2 {
+			return
+		}
+		actuatorID := parts[len(parts)-2]
+
+		value, err := parseCommandPayload(msg.Payload())
+		if err != nil {
+			log.Printf("Comando MQTT inválido para o atuador %s: %v", actuatorID, err)
+			return
+		}
+		handler(actuatorID, value)
+	})
+	if token.Wait() && token.Error() != nil {
+		return fmt.Errorf("falha ao assinar tópico %s: %w", topic, token.Error())
+	}
+	return nil
+}
+
+// parseCommandPayload interpreta o payload de um comando de atuador
+func parseCommandPayload(payload []byte) (float64, error) {
+	text := strings.TrimSpace(string(payload))
+	switch strings.ToLower(text) {
+	case "on", "true":
+		return 1, nil
+	case "off", "false":
+		return 0, nil
+	}
+
+	if value, err := strconv.ParseFloat(text, 64); err == nil {
+		return value, nil
+	}
+
+	var command struct {
+		Value *float64 `json:"value"`
+	}
+	if err := json.Unmarshal(payload, &command); err != nil || command.Value == nil {
+		return 0, fmt.Errorf("payload não reconhecido: %q", text)
+	}
+	return *command.Value, nil
+}
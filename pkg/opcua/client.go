@@ -2,28 +2,33 @@ package opcua
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go-sensors-simulator/pkg/models"
 
 	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
 	"github.com/gopcua/opcua/ua"
 )
 
 // OPCUAConfig contém as configurações do cliente OPC-UA
 type OPCUAConfig struct {
-	Endpoint    string
-	Policy      string
-	Mode        string
-	Certificate string
-	PrivateKey  string
-	Username    string
-	Password    string
-	Namespace   uint16
+	Endpoint    string `json:"endpoint"`
+	Policy      string `json:"policy"`
+	Mode        string `json:"mode"`
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"private_key"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	Namespace   uint16 `json:"namespace"`
 	// Adicionado modo de mapeamento para permitir compatibilidade com diferentes servidores
-	MappingMode string // "auto", "prosys" ou "prosys-read"
+	MappingMode string `json:"mapping_mode"` // "auto", "prosys" ou "prosys-read"
 }
 
 // OPCUAClient gerencia a comunicação via OPC-UA
@@ -31,9 +36,13 @@ type OPCUAClient struct {
 	client         *opcua.Client
 	config         OPCUAConfig
 	connected      bool
+	nodesMu        sync.Mutex            // Protege o mapa de nós, alterado quando sensores mudam em execução
 	nodeIDs        map[string]*ua.NodeID // Armazena apenas os NodeIDs
 	useProsysNodes bool                  // Indica se deve usar os nós padrão do Prosys Simulation Server
 	readOnlyMode   bool                  // Indica se está no modo apenas leitura (para servidores como Prosys)
+
+	// O servidor não aceita AddNodes: metadados são escritos apenas nas propriedades existentes
+	addNodesUnsupported atomic.Bool
 }
 
 // NewOPCUAClient cria um novo cliente OPC-UA
@@ -123,7 +132,7 @@ func (c *OPCUAClient) startReadingLoop() {
 		}
 
 		// Ler todos os nós mapeados
-		for key, nodeID := range c.nodeIDs {
+		for key, nodeID := range c.mappedNodes() {
 			value, err := c.readNodeValue(nodeID)
 			if err != nil {
 				log.Printf("Erro ao ler nó %s (%s): %v", key, nodeID, err)
@@ -135,6 +144,31 @@ func (c *OPCUAClient) startReadingLoop() {
 	}
 }
 
+// mappedNodes retorna uma cópia do mapa de nós dos sensores
+func (c *OPCUAClient) mappedNodes() map[string]*ua.NodeID {
+	c.nodesMu.Lock()
+	defer c.nodesMu.Unlock()
+
+	nodes := make(map[string]*ua.NodeID, len(c.nodeIDs))
+	for key, nodeID := range c.nodeIDs {
+		nodes[key] = nodeID
+	}
+	return nodes
+}
+
+// RemoveSensorNode descarta o nó de um sensor removido ou alterado em execução. No modo
+// automático, o nó é recriado na próxima leitura do sensor; os nós do mapeamento Prosys
+// são mantidos.
+func (c *OPCUAClient) RemoveSensorNode(sensor models.SensorConfig) {
+	if c.useProsysNodes {
+		return
+	}
+
+	c.nodesMu.Lock()
+	defer c.nodesMu.Unlock()
+	delete(c.nodeIDs, fmt.Sprintf("%s-%s", sensor.Type, sensor.ID))
+}
+
 // readNodeValue lê o valor de um nó do servidor OPC-UA
 func (c *OPCUAClient) readNodeValue(nodeID *ua.NodeID) (interface{}, error) {
 	if !c.connected {
@@ -242,6 +276,9 @@ func (c *OPCUAClient) getOrCreateNodeID(reading models.SensorReading) (*ua.NodeI
 	// Criar chave única para o sensor
 	key := fmt.Sprintf("%s-%s", reading.SensorType, reading.SensorID)
 
+	c.nodesMu.Lock()
+	defer c.nodesMu.Unlock()
+
 	// Verificar se o NodeID já existe
 	if nodeID, exists := c.nodeIDs[key]; exists {
 		log.Printf("Usando nó existente para %s: %s", key, nodeID)
@@ -255,8 +292,9 @@ func (c *OPCUAClient) getOrCreateNodeID(reading models.SensorReading) (*ua.NodeI
 	}
 
 	// Caso contrário, criar ID do nó baseado no namespace e no sensor (modo automático)
-	// Exemplo: ns=1;s=Sensors.Temperature.Temp001
-	nodeIDString := fmt.Sprintf("ns=%d;s=Sensors.%s.%s", c.config.Namespace, reading.SensorType, reading.SensorID)
+	// Exemplo: ns=1;s=Sensors.Temperature.Temp001 ou, na hierarquia de ativos,
+	// ns=1;s=Site1.Room1.Ctrl1.Temp001
+	nodeIDString := fmt.Sprintf("ns=%d;s=%s", c.config.Namespace, sensorNodePath(reading.SensorType, reading.SensorID, reading.Path()))
 	log.Printf("Criando novo nó automático para %s: %s", key, nodeIDString)
 	nodeID, err := ua.ParseNodeID(nodeIDString)
 	if err != nil {
@@ -269,6 +307,15 @@ func (c *OPCUAClient) getOrCreateNodeID(reading models.SensorReading) (*ua.NodeI
 	return nodeID, nil
 }
 
+// sensorNodePath monta o caminho do nó de um sensor: <local>.<sala>.<dispositivo>.<id> para
+// sensores na hierarquia de ativos ou Sensors.<tipo>.<id> para os demais
+func sensorNodePath(sensorType models.SensorType, sensorID string, asset models.AssetPath) string {
+	if segments := asset.Segments(); len(segments) > 0 {
+		return strings.Join(append(segments, sensorID), ".")
+	}
+	return fmt.Sprintf("Sensors.%s.%s", sensorType, sensorID)
+}
+
 // WriteReading escreve uma leitura de sensor no servidor OPC-UA
 func (c *OPCUAClient) WriteReading(reading models.SensorReading) error {
 	if !c.connected {
@@ -299,9 +346,10 @@ func (c *OPCUAClient) WriteReading(reading models.SensorReading) error {
 				NodeID:      nodeID,
 				AttributeID: ua.AttributeIDValue,
 				Value: &ua.DataValue{
-					EncodingMask:    ua.DataValueValue | ua.DataValueSourceTimestamp,
+					EncodingMask:    ua.DataValueValue | ua.DataValueStatusCode | ua.DataValueSourceTimestamp,
 					Value:           v,
-					SourceTimestamp: time.Now(),
+					Status:          ua.StatusCode(reading.Quality),
+					SourceTimestamp: reading.Timestamp,
 				},
 			},
 		},
@@ -342,3 +390,353 @@ func (c *OPCUAClient) WriteReadings(readings []models.SensorReading) error {
 
 	return nil
 }
+
+// metadataProperty é uma propriedade de inventário escrita no nó filho <nó do sensor>.<Name>
+type metadataProperty struct {
+	Name  string
+	Value interface{}
+}
+
+// metadataProperties lista as propriedades preenchidas nos metadados do sensor
+func metadataProperties(sensor models.SensorConfig) []metadataProperty {
+	m := sensor.SensorMetadata
+	var props []metadataProperty
+	for _, p := range []metadataProperty{
+		{"Name", m.Name},
+		{"Description", m.Description},
+		{"Manufacturer", m.Manufacturer},
+		{"Model", m.Model},
+		{"SerialNumber", m.Serial},
+		{"FirmwareVersion", m.Firmware},
+	} {
+		if p.Value != "" {
+			props = append(props, p)
+		}
+	}
+	if len(m.Tags) > 0 {
+		props = append(props, metadataProperty{"Tags", m.Tags})
+	}
+	if l := m.Location; l != nil {
+		if l.Room != "" {
+			props = append(props, metadataProperty{"Location.Room", l.Room})
+		}
+		props = append(props,
+			metadataProperty{"Location.X", l.X},
+			metadataProperty{"Location.Y", l.Y},
+			metadataProperty{"Location.Z", l.Z})
+	}
+	if m.CalibrationDate != nil {
+		props = append(props, metadataProperty{"CalibrationDate", m.CalibrationDate.Time})
+	}
+	if m.CalibrationDue != nil {
+		props = append(props, metadataProperty{"CalibrationDueDate", m.CalibrationDue.Time})
+	}
+	return props
+}
+
+// WriteSensorMetadata escreve os metadados de cada sensor como propriedades do seu nó
+// (ex.: ns=2;s=Sensors.temperature.temp001.SerialNumber). Um erro em um sensor é
+// registrado no log e os demais continuam sendo escritos.
+func (c *OPCUAClient) WriteSensorMetadata(sensors []models.SensorConfig) error {
+	if !c.connected {
+		return fmt.Errorf("cliente OPC-UA não está conectado")
+	}
+
+	// Os nós do mapeamento Prosys não possuem propriedades de inventário
+	if c.readOnlyMode || c.useProsysNodes {
+		log.Printf("Aviso: metadados dos sensores não são escritos no OPC-UA no modo %s", c.config.MappingMode)
+		return nil
+	}
+
+	failed := 0
+	for _, sensor := range sensors {
+		if err := c.writeSensorProperties(sensor); err != nil {
+			log.Printf("Erro ao escrever metadados do sensor %s via OPC-UA: %v", sensor.ID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("falha ao escrever metadados de %d de %d sensores", failed, len(sensors))
+	}
+	return nil
+}
+
+// writeSensorProperties escreve as propriedades do sensor que já existem no servidor e cria
+// as que faltam como nós filhos do nó do sensor (referência HasProperty), já com o valor
+func (c *OPCUAClient) writeSensorProperties(sensor models.SensorConfig) error {
+	props := metadataProperties(sensor)
+	if len(props) == 0 {
+		return nil
+	}
+
+	path := sensorNodePath(sensor.Type, sensor.ID, sensor.Path())
+	parentID, err := ua.ParseNodeID(fmt.Sprintf("ns=%d;s=%s", c.config.Namespace, path))
+	if err != nil {
+		return fmt.Errorf("falha ao analisar NodeID: %w", err)
+	}
+
+	nodeIDs := make([]*ua.NodeID, len(props))
+	values := make([]*ua.Variant, len(props))
+	readReq := &ua.ReadRequest{TimestampsToReturn: ua.TimestampsToReturnNeither}
+	for i, prop := range props {
+		if nodeIDs[i], err = ua.ParseNodeID(fmt.Sprintf("ns=%d;s=%s.%s", c.config.Namespace, path, prop.Name)); err != nil {
+			return fmt.Errorf("falha ao analisar NodeID: %w", err)
+		}
+		if values[i], err = ua.NewVariant(prop.Value); err != nil {
+			return fmt.Errorf("falha ao criar variante para a propriedade %s: %w", prop.Name, err)
+		}
+		readReq.NodesToRead = append(readReq.NodesToRead, &ua.ReadValueID{NodeID: nodeIDs[i], AttributeID: ua.AttributeIDNodeClass})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Separar as propriedades que já existem das que precisam ser criadas
+	readResp, err := c.client.Read(ctx, readReq)
+	if err != nil {
+		return fmt.Errorf("falha ao consultar propriedades: %w", err)
+	}
+	var existing, missing []int
+	for i, result := range readResp.Results {
+		if result.Status == ua.StatusBadNodeIDUnknown {
+			missing = append(missing, i)
+		} else {
+			existing = append(existing, i)
+		}
+	}
+
+	var errs []error
+	if len(missing) > 0 && c.addNodesUnsupported.Load() {
+		errs = append(errs, fmt.Errorf("%d propriedades não existem no servidor, que não permite criá-las", len(missing)))
+	} else if len(missing) > 0 {
+		addReq := &ua.AddNodesRequest{}
+		for _, i := range missing {
+			addReq.NodesToAdd = append(addReq.NodesToAdd, propertyNode(parentID, nodeIDs[i], props[i].Name, values[i]))
+		}
+		addResp, err := c.addNodes(ctx, addReq)
+		if errors.Is(err, ua.StatusBadServiceUnsupported) {
+			log.Printf("Aviso: servidor OPC-UA não permite criar nós; metadados serão escritos apenas nas propriedades existentes")
+			c.addNodesUnsupported.Store(true)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("falha ao criar %d propriedades: %w", len(missing), err))
+		} else {
+			for k, result := range addResp.Results {
+				switch i := missing[k]; result.StatusCode {
+				case ua.StatusOK:
+				case ua.StatusBadNodeIDExists:
+					existing = append(existing, i)
+				default:
+					errs = append(errs, fmt.Errorf("falha ao criar a propriedade %s, status: %v", props[i].Name, result.StatusCode))
+				}
+			}
+		}
+	}
+
+	if len(existing) > 0 {
+		writeReq := &ua.WriteRequest{}
+		for _, i := range existing {
+			writeReq.NodesToWrite = append(writeReq.NodesToWrite, &ua.WriteValue{
+				NodeID:      nodeIDs[i],
+				AttributeID: ua.AttributeIDValue,
+				Value: &ua.DataValue{
+					EncodingMask: ua.DataValueValue,
+					Value:        values[i],
+				},
+			})
+		}
+		writeResp, err := c.client.Write(ctx, writeReq)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("falha ao escrever propriedades: %w", err))...)
+		}
+		for k, status := range writeResp.Results {
+			if status != ua.StatusOK {
+				errs = append(errs, fmt.Errorf("falha ao escrever a propriedade %s, status: %v", props[existing[k]].Name, status))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// propertyNode descreve a criação de uma propriedade (PropertyType) do nó informado
+func propertyNode(parentID, nodeID *ua.NodeID, name string, value *ua.Variant) *ua.AddNodesItem {
+	valueRank := int32(-1)
+	if value.Has(ua.VariantArrayValues) {
+		valueRank = 1
+	}
+	access := uint8(ua.AccessLevelTypeCurrentRead | ua.AccessLevelTypeCurrentWrite)
+	return &ua.AddNodesItem{
+		ParentNodeID:       ua.NewExpandedNodeID(parentID, "", 0),
+		ReferenceTypeID:    ua.NewNumericNodeID(0, id.HasProperty),
+		RequestedNewNodeID: ua.NewExpandedNodeID(nodeID, "", 0),
+		BrowseName:         &ua.QualifiedName{NamespaceIndex: nodeID.Namespace(), Name: name},
+		NodeClass:          ua.NodeClassVariable,
+		NodeAttributes: ua.NewExtensionObject(&ua.VariableAttributes{
+			SpecifiedAttributes: uint32(ua.NodeAttributesMaskDisplayName | ua.NodeAttributesMaskValue |
+				ua.NodeAttributesMaskDataType | ua.NodeAttributesMaskValueRank |
+				ua.NodeAttributesMaskAccessLevel | ua.NodeAttributesMaskUserAccessLevel),
+			DisplayName:     ua.NewLocalizedText(name),
+			Description:     ua.NewLocalizedText(""),
+			Value:           value,
+			DataType:        ua.NewNumericNodeID(0, uint32(value.Type())),
+			ValueRank:       valueRank,
+			AccessLevel:     access,
+			UserAccessLevel: access,
+		}),
+		TypeDefinition: ua.NewNumericExpandedNodeID(0, id.PropertyType),
+	}
+}
+
+// addNodes envia uma requisição AddNodes, que o cliente OPC-UA não expõe diretamente
+func (c *OPCUAClient) addNodes(ctx context.Context, req *ua.AddNodesRequest) (*ua.AddNodesResponse, error) {
+	var resp *ua.AddNodesResponse
+	err := c.client.Send(ctx, req, func(v ua.Response) error {
+		r, ok := v.(*ua.AddNodesResponse)
+		if !ok {
+			return fmt.Errorf("resposta inesperada ao criar nós: %T", v)
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.ResponseHeader != nil && resp.ResponseHeader.ServiceResult != ua.StatusOK {
+		return nil, resp.ResponseHeader.ServiceResult
+	}
+	return resp, nil
+}
+
+// actuatorNodeID monta o NodeID do estado de um atuador (ex.: ns=2;s=Actuators.heater.heater01)
+func (c *OPCUAClient) actuatorNodeID(actuatorType models.ActuatorType, actuatorID, suffix string) (*ua.NodeID, error) {
+	nodeIDString := fmt.Sprintf("ns=%d;s=Actuators.%s.%s%s", c.config.Namespace, actuatorType, actuatorID, suffix)
+	nodeID, err := ua.ParseNodeID(nodeIDString)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao analisar NodeID: %w", err)
+	}
+	return nodeID, nil
+}
+
+// WriteActuatorStates escreve o estado dos atuadores no servidor OPC-UA
+func (c *OPCUAClient) WriteActuatorStates(states []models.ActuatorState) error {
+	if !c.connected {
+		return fmt.Errorf("cliente OPC-UA não está conectado")
+	}
+
+	// Servidores no modo Prosys não possuem nós de atuadores
+	if c.readOnlyMode || c.useProsysNodes {
+		return nil
+	}
+
+	for _, state := range states {
+		nodeID, err := c.actuatorNodeID(state.ActuatorType, state.ActuatorID, "")
+		if err != nil {
+			return err
+		}
+
+		v, err := ua.NewVariant(state.Value)
+		if err != nil {
+			return fmt.Errorf("falha ao criar variante para valor: %w", err)
+		}
+
+		req := &ua.WriteRequest{
+			NodesToWrite: []*ua.WriteValue{
+				{
+					NodeID:      nodeID,
+					AttributeID: ua.AttributeIDValue,
+					Value: &ua.DataValue{
+						EncodingMask:    ua.DataValueValue | ua.DataValueSourceTimestamp,
+						Value:           v,
+						SourceTimestamp: state.Timestamp,
+					},
+				},
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		resp, err := c.client.Write(ctx, req)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("falha ao escrever estado do atuador: %w", err)
+		}
+		if resp.Results[0] != ua.StatusOK {
+			return fmt.Errorf("falha ao escrever estado do atuador, status: %v", resp.Results[0])
+		}
+	}
+
+	return nil
+}
+
+// WatchActuatorCommands lê periodicamente os nós de comando dos atuadores
+// (ex.: ns=2;s=Actuators.heater.heater01.Command) e chama o handler quando o valor escrito
+// por um cliente OPC-UA muda
+func (c *OPCUAClient) WatchActuatorCommands(actuators []models.ActuatorConfig, handler func(actuatorID string, value float64)) error {
+	if !c.connected {
+		return fmt.Errorf("cliente OPC-UA não está conectado")
+	}
+
+	commandNodes := make(map[string]*ua.NodeID, len(actuators))
+	for _, actuator := range actuators {
+		nodeID, err := c.actuatorNodeID(actuator.Type, actuator.ID, ".Command")
+		if err != nil {
+			return err
+		}
+		commandNodes[actuator.ID] = nodeID
+	}
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		lastCommands := make(map[string]float64)
+		for range ticker.C {
+			if !c.connected {
+				return
+			}
+
+			for actuatorID, nodeID := range commandNodes {
+				raw, err := c.readNodeValue(nodeID)
+				if err != nil {
+					continue
+				}
+				value, ok := toFloat64(raw)
+				if !ok {
+					log.Printf("Comando OPC-UA com tipo não suportado para o atuador %s: %T", actuatorID, raw)
+					continue
+				}
+
+				// Repassar apenas mudanças, para não sobrescrever comandos de outras origens.
+				// O primeiro valor lido é a referência, e não um comando.
+				last, seen := lastCommands[actuatorID]
+				lastCommands[actuatorID] = value
+				if seen && last != value {
+					handler(actuatorID, value)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// toFloat64 converte valores numéricos e booleanos lidos do servidor OPC-UA
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// actuator é um atuador virtual e seu nível de acionamento atual
+type actuator struct {
+	config models.ActuatorConfig
+	level  float64
+}
+
+// WithActuators define os atuadores virtuais do simulador
+func WithActuators(configs []models.ActuatorConfig) Option {
+	return func(s *Simulator) {
+		s.actuatorConfigs = configs
+	}
+}
+
+// WithActuatorCallback define a função chamada a cada ciclo com o estado dos atuadores
+func WithActuatorCallback(callback func([]models.ActuatorState)) Option {
+	return func(s *Simulator) {
+		s.actuatorCallback = callback
+	}
+}
+
+// newActuators valida e cria os atuadores configurados
+func (s *Simulator) newActuators() error {
+	s.actuators = make(map[string]*actuator, len(s.actuatorConfigs))
+	for _, config := range s.actuatorConfigs {
+		if config.ID == "" {
+			return fmt.Errorf("atuador sem id")
+		}
+		if _, exists := s.actuators[config.ID]; exists {
+			return fmt.Errorf("atuador duplicado: %s", config.ID)
+		}
+		if !models.ValidActuatorType(config.Type) {
+			return fmt.Errorf("tipo de atuador desconhecido %q (atuador %s)", config.Type, config.ID)
+		}
+		if config.Room != "" {
+			if _, ok := s.growRooms[config.Room]; !ok {
+				return fmt.Errorf("sala de cultivo desconhecida %q para o atuador %s", config.Room, config.ID)
+			}
+		}
+		for _, effect := range config.Effects {
+			if !s.hasSensor(effect.SensorID) {
+				return fmt.Errorf("sensor desconhecido %s no efeito do atuador %s", effect.SensorID, config.ID)
+			}
+			generator, ok := s.generators[effect.SensorID]
+			if !ok {
+				return fmt.Errorf("o sensor derivado %s não pode receber efeitos do atuador %s", effect.SensorID, config.ID)
+			}
+			// O efeito altera o último valor do sensor, ignorado por geradores absolutos
+			if !followsLastValue(generator) {
+				return fmt.Errorf("o atuador %s não pode atuar sobre o sensor %s, cujo gerador não parte do último valor", config.ID, effect.SensorID)
+			}
+		}
+		s.actuators[config.ID] = &actuator{config: config}
+	}
+	return nil
+}
+
+// SetActuator define o nível de acionamento de um atuador (0 = desligado, 1 = potência máxima)
+func (s *Simulator) SetActuator(id string, level float64) error {
+	if level < 0 || level > 1 {
+		return fmt.Errorf("nível do atuador deve estar entre 0 e 1, recebido %v", level)
+	}
+
+	s.actuatorsMu.Lock()
+	defer s.actuatorsMu.Unlock()
+
+	a, ok := s.actuators[id]
+	if !ok {
+		return fmt.Errorf("atuador desconhecido: %s", id)
+	}
+	a.level = level
+	return nil
+}
+
+// Actuators retorna o estado atual de todos os atuadores, ordenados pelo ID
+func (s *Simulator) Actuators() []models.ActuatorState {
+	return s.actuatorStates(s.clock.Now())
+}
+
+// ActuatorConfigs retorna as configurações dos atuadores
+func (s *Simulator) ActuatorConfigs() []models.ActuatorConfig {
+	return s.actuatorConfigs
+}
+
+// actuatorStates monta o estado dos atuadores com o timestamp informado
+func (s *Simulator) actuatorStates(now time.Time) []models.ActuatorState {
+	s.actuatorsMu.Lock()
+	defer s.actuatorsMu.Unlock()
+
+	states := make([]models.ActuatorState, 0, len(s.actuators))
+	for _, a := range s.actuators {
+		states = append(states, models.ActuatorState{
+			ActuatorID:   a.config.ID,
+			ActuatorType: a.config.Type,
+			Value:        a.level,
+			Timestamp:    now,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ActuatorID < states[j].ActuatorID })
+	return states
+}
+
+// applyActuators aplica os efeitos diretos dos atuadores aos sensores e retorna os níveis
+// de acionamento de cada sala de cultivo, por tipo de atuador
+func (s *Simulator) applyActuators(step time.Duration) map[string]map[models.ActuatorType]float64 {
+	s.actuatorsMu.Lock()
+	defer s.actuatorsMu.Unlock()
+
+	roomLevels := make(map[string]map[models.ActuatorType]float64)
+	for _, a := range s.actuators {
+		for _, effect := range a.config.Effects {
+			s.lastValues[effect.SensorID] += effect.Rate * a.level * step.Hours()
+		}
+
+		if a.config.Room == "" {
+			continue
+		}
+		levels, ok := roomLevels[a.config.Room]
+		if !ok {
+			levels = make(map[models.ActuatorType]float64)
+			roomLevels[a.config.Room] = levels
+		}
+		// Vários atuadores do mesmo tipo na sala somam potência
+		levels[a.config.Type] += a.level
+	}
+	return roomLevels
+}
+
+// publishActuators notifica o callback de atuadores com o estado atual
+func (s *Simulator) publishActuators(now time.Time) {
+	if s.actuatorCallback == nil || len(s.actuators) == 0 {
+		return
+	}
+	s.actuatorCallback(s.actuatorStates(now))
+}
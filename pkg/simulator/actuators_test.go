@@ -0,0 +1,47 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func TestActuatorEffectsRequireRelativeGenerator(t *testing.T) {
+	sensors := testSensors(2)
+	sensors[0].Generator = &models.GeneratorConfig{Name: "random_walk"}
+	sensors[1].Generator = &models.GeneratorConfig{Name: "constant"}
+	actuator := func(sensorID string) Option {
+		return WithActuators([]models.ActuatorConfig{{
+			ID:      "heater01",
+			Type:    models.Heater,
+			Effects: []models.ActuatorEffect{{SensorID: sensorID, Rate: 2}},
+		}})
+	}
+
+	if _, err := NewSimulator(sensors, nil, actuator(sensors[1].ID)); err == nil {
+		t.Fatal("efeito de atuador aceito em gerador constante")
+	}
+
+	// O efeito soma a variação ao último valor do passeio aleatório
+	sim := newTestSimulator(t, sensors, WithSeed(1), actuator(sensors[0].ID))
+	if err := sim.SetActuator("heater01", 1); err != nil {
+		t.Fatalf("SetActuator: %v", err)
+	}
+	before := sim.lastValues[sensors[0].ID]
+	sim.applyActuators(time.Hour)
+	if got := sim.lastValues[sensors[0].ID]; got != before+2 {
+		t.Fatalf("valor após uma hora = %v, esperado %v", got, before+2)
+	}
+
+	// Trocar o gerador do sensor por um absoluto em execução anularia o efeito
+	update := sensors[0]
+	update.Generator = &models.GeneratorConfig{Name: "constant"}
+	if _, err := sim.UpdateSensor(update.ID, update); err == nil {
+		t.Fatal("UpdateSensor aceitou um gerador absoluto em sensor com efeito de atuador")
+	}
+	update.Generator = &models.GeneratorConfig{Name: "ornstein_uhlenbeck"}
+	if _, err := sim.UpdateSensor(update.ID, update); err != nil {
+		t.Fatalf("UpdateSensor: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// validateBoundary verifica o modo de limite e a faixa de operação do sensor
+func validateBoundary(config models.SensorConfig) error {
+	if b := config.Boundary; b != nil {
+		if b.Mode != "" && !models.ValidBoundaryMode(b.Mode) {
+			return fmt.Errorf("modo de limite desconhecido %q (sensor %s)", b.Mode, config.ID)
+		}
+		if b.Rate < 0 {
+			return fmt.Errorf("rate do limite não pode ser negativo (sensor %s)", config.ID)
+		}
+		if b.Knee < 0 || b.Knee > 0.5 {
+			return fmt.Errorf("knee do limite deve estar entre 0 e 0.5 (sensor %s)", config.ID)
+		}
+		if b.Target != nil && (*b.Target < config.MinValue || *b.Target > config.MaxValue) {
+			return fmt.Errorf("alvo do limite fora da faixa física (sensor %s)", config.ID)
+		}
+	}
+
+	if r := config.OperatingRange; r != nil {
+		if r.Min >= r.Max {
+			return fmt.Errorf("faixa de operação com mínimo maior ou igual ao máximo (sensor %s)", config.ID)
+		}
+		if r.Min < config.MinValue || r.Max > config.MaxValue {
+			return fmt.Errorf("faixa de operação fora da faixa física (sensor %s)", config.ID)
+		}
+	}
+	return nil
+}
+
+// applyBoundary traz para a faixa física um valor gerado, conforme o modo de limite do sensor.
+// Retorna o valor tratado e o rótulo do limite ultrapassado ("" se o valor estava na faixa).
+func applyBoundary(config models.SensorConfig, value float64, step time.Duration, rng *rand.Rand) (float64, models.AnomalyLabel) {
+	lo, hi := config.MinValue, config.MaxValue
+	mode := models.BoundaryClamp
+	if config.Boundary != nil && config.Boundary.Mode != "" {
+		mode = config.Boundary.Mode
+	}
+
+	// A reversão à média atua a cada amostra, antes da verificação dos limites
+	if mode == models.BoundaryMeanReversion {
+		rate := 1.0
+		if config.Boundary.Rate > 0 {
+			rate = config.Boundary.Rate
+		}
+		value += (boundaryTarget(config) - value) * (1 - math.Exp(-rate*step.Hours()))
+	}
+
+	var label models.AnomalyLabel
+	switch {
+	case value < lo:
+		label = models.LabelClampMin
+	case value > hi:
+		label = models.LabelClampMax
+	}
+
+	switch mode {
+	case models.BoundaryReflect:
+		if label != "" && hi > lo {
+			// Reflexões sucessivas equivalem a um dente de serra de período 2×faixa
+			span := hi - lo
+			x := math.Mod(value-lo, 2*span)
+			if x < 0 {
+				x += 2 * span
+			}
+			if x > span {
+				x = 2*span - x
+			}
+			value = lo + x
+		}
+	case models.BoundarySoftLimit:
+		value = softLimit(value, lo, hi, config.Boundary.Knee)
+	case models.BoundaryWrap:
+		if label != "" && hi > lo {
+			x := math.Mod(value-lo, hi-lo)
+			if x < 0 {
+				x += hi - lo
+			}
+			value = lo + x
+		}
+	case models.BoundarySaturate, models.BoundaryMeanReversion:
+		value = math.Max(lo, math.Min(hi, value))
+	default:
+		// Comportamento original: voltar para dentro do limite com um ruído aleatório
+		if value < lo {
+			value = lo + rng.Float64()*config.NoiseAmplitude
+		}
+		if value > hi {
+			value = hi - rng.Float64()*config.NoiseAmplitude
+		}
+	}
+	return value, label
+}
+
+// softLimit mantém o valor inalterado no centro da faixa e o comprime com tanh na fração
+// knee junto a cada limite, aproximando-se dos limites sem ultrapassá-los
+func softLimit(value, lo, hi, knee float64) float64 {
+	if knee == 0 {
+		knee = 0.1
+	}
+	width := (hi - lo) * knee
+	if width <= 0 {
+		return math.Max(lo, math.Min(hi, value))
+	}
+	if upper := hi - width; value > upper {
+		return upper + width*math.Tanh((value-upper)/width)
+	}
+	if lower := lo + width; value < lower {
+		return lower - width*math.Tanh((lower-value)/width)
+	}
+	return value
+}
+
+// boundaryTarget retorna o alvo da reversão à média: o configurado ou o centro da faixa
+// de operação, ou da faixa física se não houver faixa de operação
+func boundaryTarget(config models.SensorConfig) float64 {
+	if config.Boundary.Target != nil {
+		return *config.Boundary.Target
+	}
+	if r := config.OperatingRange; r != nil {
+		return (r.Min + r.Max) / 2
+	}
+	return (config.MinValue + config.MaxValue) / 2
+}
+
+// operatingLabel retorna o rótulo de alarme de uma leitura fora da faixa de operação
+func operatingLabel(config models.SensorConfig, value float64) models.AnomalyLabel {
+	r := config.OperatingRange
+	switch {
+	case r == nil:
+		return ""
+	case value < r.Min:
+		return models.LabelAlarmLow
+	case value > r.Max:
+		return models.LabelAlarmHigh
+	}
+	return ""
+}
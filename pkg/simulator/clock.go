@@ -0,0 +1,156 @@
+package simulator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock fornece o horário usado pelo simulador para timestamps e ciclos diários
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock usa o relógio do sistema
+type realClock struct{}
+
+// Now retorna o horário atual do sistema
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock é um relógio controlado manualmente, útil para execuções reproduzíveis.
+// Quando usado com Start, o relógio avança exatamente um intervalo a cada ciclo.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock cria um relógio manual iniciando no horário informado
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now retorna o horário atual do relógio manual
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set define o horário do relógio manual
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance avança o relógio manual pela duração informada
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// SpeedController é implementado por relógios com velocidade ajustável
+type SpeedController interface {
+	Speed() float64
+	SetSpeed(speed float64) error
+}
+
+// VirtualClock é um relógio virtual que avança a uma velocidade múltipla do relógio real.
+// Com velocidade 3600, por exemplo, cada segundo real corresponde a uma hora simulada.
+type VirtualClock struct {
+	mu       sync.Mutex
+	base     time.Time // Horário simulado no último ajuste de velocidade
+	wallBase time.Time // Horário real no último ajuste de velocidade
+	speed    float64
+	paused   bool // Horário simulado congelado em base
+}
+
+// NewVirtualClock cria um relógio virtual iniciando em start com a velocidade informada
+func NewVirtualClock(start time.Time, speed float64) (*VirtualClock, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("a velocidade do relógio deve ser positiva, recebido %v", speed)
+	}
+	return &VirtualClock{
+		base:     start,
+		wallBase: time.Now(),
+		speed:    speed,
+	}, nil
+}
+
+// Now retorna o horário simulado atual
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nowLocked()
+}
+
+// nowLocked calcula o horário simulado; deve ser chamado com o mutex travado
+func (c *VirtualClock) nowLocked() time.Time {
+	if c.paused {
+		return c.base
+	}
+	elapsed := time.Since(c.wallBase)
+	return c.base.Add(time.Duration(float64(elapsed) * c.speed))
+}
+
+// Speed retorna o multiplicador de velocidade atual
+func (c *VirtualClock) Speed() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.speed
+}
+
+// SetSpeed altera o multiplicador de velocidade sem descontinuidade no horário simulado
+func (c *VirtualClock) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("a velocidade do relógio deve ser positiva, recebido %v", speed)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.base = c.nowLocked()
+	c.wallBase = time.Now()
+	c.speed = speed
+	return nil
+}
+
+// Pause congela o horário simulado até Resume
+func (c *VirtualClock) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.base = c.nowLocked()
+	c.paused = true
+}
+
+// Resume volta a avançar o horário simulado a partir de onde foi congelado
+func (c *VirtualClock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.wallBase = time.Now()
+	c.paused = false
+}
+
+// Paused indica se o horário simulado está congelado
+func (c *VirtualClock) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Set define o horário simulado atual, mantendo a velocidade
+func (c *VirtualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.base = t
+	c.wallBase = time.Now()
+}
+
+// Advance avança o horário simulado pela duração informada
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.base = c.base.Add(d)
+}
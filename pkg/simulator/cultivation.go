@@ -0,0 +1,256 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func init() {
+	RegisterGenerator("photoperiod", newPhotoperiodGenerator)
+	RegisterGenerator("dry_down", newDryDownGenerator)
+	RegisterGenerator("dosing", newDosingGenerator)
+}
+
+// photoperiodFraction retorna a fração de luz (0 a 1) de um fotoperíodo que acende às
+// onHour e dura hours horas, com rampas de rampMinutes no acendimento e no desligamento
+func photoperiodFraction(now time.Time, onHour, hours, rampMinutes float64) float64 {
+	hour := float64(now.Hour()) + float64(now.Minute())/60 + float64(now.Second())/3600
+	sinceOn := math.Mod(hour-onHour+24, 24)
+	if sinceOn >= hours {
+		return 0
+	}
+
+	ramp := rampMinutes / 60
+	if ramp <= 0 {
+		return 1
+	}
+	return math.Min(1, math.Min(sinceOn/ramp, (hours-sinceOn)/ramp))
+}
+
+// measured separa o valor real de um processo lento do ruído de medição, evitando que o
+// ruído se acumule como um passeio aleatório. Se o último valor for alterado externamente
+// (cenários, atuadores, limites), o processo é ressincronizado a partir dele.
+type measured struct {
+	value, reported float64
+	valid           bool
+}
+
+// base retorna o valor real do processo a partir do último valor do sensor
+func (m *measured) base(last float64) float64 {
+	if !m.valid || last != m.reported {
+		m.value = last
+		m.valid = true
+	}
+	return m.value
+}
+
+// report guarda o novo valor real e retorna a leitura com ruído
+func (m *measured) report(value, noise float64) float64 {
+	m.value = value
+	m.reported = value + noise
+	return m.reported
+}
+
+// GeneratorState é o estado interno de um gerador com memória além do último valor
+type GeneratorState struct {
+	Value      jsonFloat `json:"value"`    // Valor real do processo
+	Reported   jsonFloat `json:"reported"` // Último valor reportado, com ruído
+	Valid      bool      `json:"valid"`
+	Irrigating bool      `json:"irrigating,omitempty"`
+}
+
+// statefulGenerator é implementado por geradores cujo estado é salvo nos snapshots
+type statefulGenerator interface {
+	saveState() GeneratorState
+	loadState(state GeneratorState)
+}
+
+// save retorna o estado do processo
+func (m *measured) save() GeneratorState {
+	return GeneratorState{Value: jsonFloat(m.value), Reported: jsonFloat(m.reported), Valid: m.valid}
+}
+
+// load retoma o processo a partir de um estado salvo
+func (m *measured) load(state GeneratorState) {
+	m.value, m.reported, m.valid = float64(state.Value), float64(state.Reported), state.Valid
+}
+
+// photoperiodParams são os padrões do gerador "photoperiod" para cada tipo de sensor:
+// valor com luz, valor sem luz e constante de tempo em segundos
+var photoperiodParams = map[models.SensorType][3]float64{
+	models.CO2:              {1000, 600, 900},  // Enriquecimento com luz, respiração à noite
+	models.PPFD:             {800, 0, 30},      // Luminárias LED típicas de vegetativo
+	models.WaterTemperature: {21, 19, 7200},    // Reservatório acompanha a sala lentamente
+	models.LeafTemperature:  {24.5, 20.5, 600}, // Folha abaixo do ar pela transpiração
+}
+
+// photoperiodGenerator aproxima o último valor de um alvo que depende do fotoperíodo,
+// como uma grandeza de primeira ordem com constante de tempo tau
+type photoperiodGenerator struct {
+	day, night         float64
+	onHour, lightHours float64
+	rampMinutes, tau   float64
+	noise              float64
+	state              measured
+}
+
+// Next calcula o novo valor aproximando-o do alvo do horário atual
+func (g *photoperiodGenerator) Next(ctx *GeneratorContext) float64 {
+	value := g.state.base(ctx.LastValue)
+	light := photoperiodFraction(ctx.Now, g.onHour, g.lightHours, g.rampMinutes)
+	target := g.night + (g.day-g.night)*light
+	value += (target - value) * (1 - math.Exp(-ctx.Step.Seconds()/g.tau))
+	return g.state.report(value, uniformNoise(ctx.Rand, g.noise))
+}
+
+// saveState e loadState implementam statefulGenerator
+func (g *photoperiodGenerator) saveState() GeneratorState      { return g.state.save() }
+func (g *photoperiodGenerator) loadState(state GeneratorState) { g.state.load(state) }
+
+// newPhotoperiodGenerator cria um gerador que segue o fotoperíodo da sala.
+// Parâmetros: day e night (valores com e sem luz, padrão por tipo), lights_on (hora, padrão: 6),
+// light_hours (padrão: 18), ramp_minutes (padrão: 15), tau em segundos (padrão por tipo),
+// noise (padrão: noise_amplitude)
+func newPhotoperiodGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	defaults, ok := photoperiodParams[config.Type]
+	if !ok {
+		defaults = [3]float64{config.MaxValue, config.MinValue, 600}
+	}
+
+	g := &photoperiodGenerator{
+		day:         param(params, "day", defaults[0]),
+		night:       param(params, "night", defaults[1]),
+		onHour:      param(params, "lights_on", 6),
+		lightHours:  param(params, "light_hours", 18),
+		rampMinutes: param(params, "ramp_minutes", 15),
+		tau:         param(params, "tau", defaults[2]),
+		noise:       param(params, "noise", config.NoiseAmplitude),
+	}
+	if g.tau <= 0 {
+		return nil, fmt.Errorf("tau deve ser positivo, recebido %v", g.tau)
+	}
+	if g.lightHours < 0 || g.lightHours > 24 {
+		return nil, fmt.Errorf("light_hours deve estar entre 0 e 24, recebido %v", g.lightHours)
+	}
+	return g, nil
+}
+
+// dryDownGenerator simula o ciclo de secagem e irrigação do substrato: a umidade cai
+// com a evapotranspiração (mais rápido com luz) e, abaixo do limite, a irrigação a
+// eleva até a capacidade de campo
+type dryDownGenerator struct {
+	rate, irrigationRate float64
+	threshold, capacity  float64
+	onHour, lightHours   float64
+	noise                float64
+	irrigating           bool
+	state                measured
+}
+
+// Next calcula a nova umidade do substrato
+func (g *dryDownGenerator) Next(ctx *GeneratorContext) float64 {
+	h := ctx.Step.Hours()
+	value := g.state.base(ctx.LastValue)
+	if value <= g.threshold {
+		g.irrigating = true
+	}
+	if g.irrigating {
+		value += g.irrigationRate * h
+		if value >= g.capacity {
+			value = g.capacity
+			g.irrigating = false
+		}
+	} else {
+		light := photoperiodFraction(ctx.Now, g.onHour, g.lightHours, 0)
+		value -= g.rate * (0.3 + 0.7*light) * h
+	}
+	return g.state.report(value, uniformNoise(ctx.Rand, g.noise))
+}
+
+// saveState e loadState implementam statefulGenerator, incluindo a irrigação em andamento
+func (g *dryDownGenerator) saveState() GeneratorState {
+	state := g.state.save()
+	state.Irrigating = g.irrigating
+	return state
+}
+
+func (g *dryDownGenerator) loadState(state GeneratorState) {
+	g.state.load(state)
+	g.irrigating = state.Irrigating
+}
+
+// newDryDownGenerator cria o gerador de umidade do substrato.
+// Parâmetros: rate (secagem com luz, %/h, padrão: 1.5), irrigation_rate (%/h, padrão: 120),
+// threshold (início da irrigação, padrão: 35), capacity (fim da irrigação, padrão: 60),
+// lights_on (padrão: 6), light_hours (padrão: 18), noise (padrão: noise_amplitude)
+func newDryDownGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	g := &dryDownGenerator{
+		rate:           param(params, "rate", 1.5),
+		irrigationRate: param(params, "irrigation_rate", 120),
+		threshold:      param(params, "threshold", 35),
+		capacity:       param(params, "capacity", 60),
+		onHour:         param(params, "lights_on", 6),
+		lightHours:     param(params, "light_hours", 18),
+		noise:          param(params, "noise", config.NoiseAmplitude),
+	}
+	if g.rate < 0 || g.irrigationRate <= 0 {
+		return nil, fmt.Errorf("rate não pode ser negativo e irrigation_rate deve ser positivo")
+	}
+	if g.threshold >= g.capacity {
+		return nil, fmt.Errorf("threshold (%v) deve ser menor que capacity (%v)", g.threshold, g.capacity)
+	}
+	return g, nil
+}
+
+// dosingParams são os padrões do gerador "dosing" para cada tipo de sensor:
+// deriva por hora, valor após a correção e limite que dispara a dosagem
+var dosingParams = map[models.SensorType][3]float64{
+	models.PH: {0.03, 5.8, 6.3}, // pH sobe com a absorção de nitrato e é corrigido com ácido
+	models.EC: {0.02, 1.6, 2.2}, // EC sobe com a evaporação e é corrigida com água
+}
+
+// dosingGenerator simula uma solução nutritiva controlada por dosagem: o valor deriva
+// lentamente e, ao atingir o limite, a dosagem o traz de volta
+type dosingGenerator struct {
+	rate, reset, limit float64
+	noise              float64
+	state              measured
+}
+
+// Next calcula o novo valor da solução, aplicando a dosagem ao atingir o limite
+func (g *dosingGenerator) Next(ctx *GeneratorContext) float64 {
+	value := g.state.base(ctx.LastValue) + g.rate*ctx.Step.Hours()
+	if (g.rate > 0 && value >= g.limit) || (g.rate < 0 && value <= g.limit) {
+		value = g.reset
+	}
+	return g.state.report(value, uniformNoise(ctx.Rand, g.noise))
+}
+
+// saveState e loadState implementam statefulGenerator
+func (g *dosingGenerator) saveState() GeneratorState      { return g.state.save() }
+func (g *dosingGenerator) loadState(state GeneratorState) { g.state.load(state) }
+
+// newDosingGenerator cria o gerador de uma solução nutritiva controlada por dosagem.
+// Parâmetros: rate (deriva por hora, padrão por tipo), reset (valor após a dosagem) e
+// limit (limite da dosagem), noise (padrão: noise_amplitude)
+func newDosingGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	defaults, ok := dosingParams[config.Type]
+	if !ok {
+		span := config.MaxValue - config.MinValue
+		defaults = [3]float64{span / 100, config.MinValue + span*0.4, config.MinValue + span*0.6}
+	}
+
+	g := &dosingGenerator{
+		rate:  param(params, "rate", defaults[0]),
+		reset: param(params, "reset", defaults[1]),
+		limit: param(params, "limit", defaults[2]),
+		noise: param(params, "noise", config.NoiseAmplitude),
+	}
+	if (g.rate > 0 && g.limit <= g.reset) || (g.rate < 0 && g.limit >= g.reset) {
+		return nil, fmt.Errorf("limit (%v) deve ficar no sentido da deriva a partir de reset (%v)", g.limit, g.reset)
+	}
+	return g, nil
+}
@@ -0,0 +1,116 @@
+package simulator
+
+import (
+	"math"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func init() {
+	RegisterGenerator("daily_temperature", newDailyTemperatureGenerator)
+	RegisterGenerator("daily_humidity", newDailyHumidityGenerator)
+	RegisterGenerator("daily_light", newDailyLightGenerator)
+	RegisterGenerator("daily_pressure", newDailyPressureGenerator)
+}
+
+// dailyCycle é o modelo original do simulador: último valor + ruído + drift + um fator
+// sazonal/cíclico que depende da hora do dia
+type dailyCycle struct {
+	seasonal func(ctx *GeneratorContext, hourOfDay float64) float64
+}
+
+// Next calcula o novo valor a partir do último valor, ruído, drift e fator sazonal
+func (g *dailyCycle) Next(ctx *GeneratorContext) float64 {
+	// Gerar uma variação aleatória dentro da amplitude de ruído configurada
+	noise := (ctx.Rand.Float64()*2 - 1) * ctx.Config.NoiseAmplitude
+
+	// Adicionar um drift lento ao longo do tempo
+	drift := *ctx.Drift * ctx.LastValue
+
+	// Ocasionalmente, mudar a direção do drift
+	if ctx.Rand.Float64() < 0.05 { // 5% de chance de mudar a direção
+		*ctx.Drift = -*ctx.Drift + (ctx.Rand.Float64()*0.01 - 0.005) // Mudar e adicionar pequena variação
+	}
+
+	// Hora do dia com fração para transições mais suaves
+	hourOfDay := float64(ctx.Now.Hour()) + float64(ctx.Now.Minute())/60.0
+
+	return ctx.LastValue + noise + g.seasonal(ctx, hourOfDay) + drift
+}
+
+// newDailyTemperatureGenerator cria o modelo de temperatura diária.
+// Parâmetros: amplitude do ciclo diário (padrão: 2)
+func newDailyTemperatureGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	amplitude := param(params, "amplitude", 2)
+
+	return &dailyCycle{seasonal: func(ctx *GeneratorContext, hourOfDay float64) float64 {
+		// Temperatura mais alta durante o dia, mais baixa à noite
+		dailyCycle := math.Sin((hourOfDay / 24) * 2 * math.Pi)
+		// Adicionar pequena variação de minuto em minuto
+		minuteCycle := math.Sin((float64(ctx.Now.Minute())/60)*2*math.Pi) * 0.3
+		return dailyCycle*amplitude + minuteCycle
+	}}, nil
+}
+
+// newDailyHumidityGenerator cria o modelo de umidade diária.
+// Parâmetros: amplitude do ciclo diário (padrão: 3), rain_probability por ciclo (padrão: 0.01)
+func newDailyHumidityGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	amplitude := param(params, "amplitude", 3)
+	rainProbability := param(params, "rain_probability", 0.01)
+
+	return &dailyCycle{seasonal: func(ctx *GeneratorContext, hourOfDay float64) float64 {
+		// Umidade inversamente relacionada à temperatura
+		dailyCycle := -math.Sin((hourOfDay / 24) * 2 * math.Pi)
+		// Adicionar pico de umidade aleatório ocasional (simulando chuva/irrigação)
+		if ctx.Rand.Float64() < rainProbability {
+			ctx.Label(models.LabelRain)
+			return dailyCycle*amplitude + ctx.Rand.Float64()*10
+		}
+		// Variação normal
+		minuteCycle := math.Sin((float64(ctx.Now.Minute())/30)*2*math.Pi) * 0.8
+		return dailyCycle*amplitude + minuteCycle
+	}}, nil
+}
+
+// newDailyLightGenerator cria o modelo de luminosidade diária.
+// Parâmetros: peak (padrão: 400), sunrise e sunset em horas (padrão: 5 e 19),
+// cloud_probability por ciclo (padrão: 0.1)
+func newDailyLightGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	peak := param(params, "peak", 400)
+	sunrise := param(params, "sunrise", 5)
+	sunset := param(params, "sunset", 19)
+	cloudProbability := param(params, "cloud_probability", 0.1)
+
+	return &dailyCycle{seasonal: func(ctx *GeneratorContext, hourOfDay float64) float64 {
+		// Luz alta durante o dia, baixa à noite com transições suaves
+		if hourOfDay >= sunrise && hourOfDay <= sunset {
+			// Dia (forma de sino)
+			midday := (sunrise + sunset) / 2
+			hourFactor := math.Pow((hourOfDay-midday)/((sunset-sunrise)/2), 2)
+			baseFactor := peak * math.Exp(-hourFactor)
+			// Adicionar variação para simular passagem de nuvens
+			cloudFactor := 1.0
+			if ctx.Rand.Float64() < cloudProbability {
+				cloudFactor = 0.5 + ctx.Rand.Float64()*0.3
+				ctx.Label(models.LabelCloud)
+			}
+			return baseFactor * cloudFactor
+		}
+		// Noite - pequena luz para simular luz ambiente/artificial
+		return ctx.Rand.Float64() * 5
+	}}, nil
+}
+
+// newDailyPressureGenerator cria o modelo de pressão atmosférica.
+// Parâmetros: amplitude do ciclo diário (padrão: 0.5), weekly_amplitude (padrão: 1)
+func newDailyPressureGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	amplitude := param(params, "amplitude", 0.5)
+	weeklyAmplitude := param(params, "weekly_amplitude", 1)
+
+	return &dailyCycle{seasonal: func(ctx *GeneratorContext, hourOfDay float64) float64 {
+		// Pressão com variação menor e mais lenta + tendências aleatórias
+		dailyCycle := math.Sin((hourOfDay/48)*2*math.Pi) * amplitude
+		weekCycle := math.Sin((float64(ctx.Now.Weekday())/7)*2*math.Pi) * weeklyAmplitude
+		return dailyCycle + weekCycle
+	}}, nil
+}
@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// derivedSensor é um sensor calculado a partir das leituras de outros sensores
+type derivedSensor struct {
+	index int // Posição do sensor na configuração
+	expr  *expression
+}
+
+// newDerivedSensors compila as expressões dos sensores derivados e os ordena de forma que
+// cada sensor seja calculado depois das suas dependências. Ciclos são rejeitados.
+func newDerivedSensors(configs []models.SensorConfig, index map[string]int) ([]derivedSensor, error) {
+	exprs := make(map[string]*expression)
+	for _, config := range configs {
+		if !config.Derived() {
+			continue
+		}
+		if config.Generator != nil {
+			return nil, fmt.Errorf("o sensor derivado %s não pode definir um gerador", config.ID)
+		}
+		expr, err := parseExpression(config.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("sensor derivado %s: %w", config.ID, err)
+		}
+		for _, dep := range expr.deps {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("o sensor derivado %s depende do sensor desconhecido %q", config.ID, dep)
+			}
+		}
+		exprs[config.ID] = expr
+	}
+
+	// Ordenação topológica por busca em profundidade
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(exprs))
+	var path []string
+	var order []derivedSensor
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		expr, ok := exprs[id]
+		if !ok || state[id] == visited {
+			return nil
+		}
+		if state[id] == visiting {
+			cycle := append(path[slices.Index(path, id):], id)
+			return fmt.Errorf("ciclo de dependência entre sensores derivados: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range expr.deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		order = append(order, derivedSensor{index: index[id], expr: expr})
+		return nil
+	}
+
+	for _, config := range configs {
+		if err := visit(config.ID); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// hasSensor indica se o sensor existe, físico ou derivado
+func (s *Simulator) hasSensor(id string) bool {
+	_, ok := s.sensorIndex[id]
+	return ok
+}
+
+// simulateDerived calcula os sensores derivados em ordem de dependência, em cada um dos
+// seus instantes de amostragem no ciclo. As expressões usam o último valor reportado
+// (com falhas) de cada dependência; se alguma dependência estiver sem leitura válida
+// (dropout), a leitura derivada é descartada.
+func (s *Simulator) simulateDerived(results []sensorResult, now time.Time, faults map[string][]*fault) {
+	for _, d := range s.derived {
+		config := s.configs[d.index]
+		schedule := s.schedules[d.index]
+		result := sensorResult{value: s.lastValues[config.ID]}
+
+		for _, at := range schedule.due(config, now, s.rng) {
+			step := at.Sub(schedule.last)
+			env := &exprEnv{values: s.reported, now: at, step: step}
+			schedule.last = at
+			result.sampled = true
+			result.dropped = true
+
+			available := true
+			for _, dep := range d.expr.deps {
+				if _, ok := s.reported[dep]; !ok {
+					available = false
+					break
+				}
+			}
+			if !available {
+				continue
+			}
+
+			value := d.expr.root.eval(env)
+			measured := value
+			if chain := s.measurements[d.index]; chain != nil {
+				measured = chain.apply(value, step)
+			}
+			reported, active, dropped := applyFaults(s.rng, config, measured, at, faults[config.ID])
+			result.value = value
+			result.dropped = dropped
+			if dropped {
+				result.dropouts = append(result.dropouts, droppedReading(config, at, nil, active))
+				continue
+			}
+			reading := models.NewSensorReadingAt(config, reported, at)
+			reading.Faults = active
+			reading.Labels = readingLabels(config, reported, nil, active)
+			reading.Quality = readingQuality(config, reported, nil, active)
+			result.readings = append(result.readings, reading)
+		}
+
+		result.schedule = schedule
+		results[d.index] = result
+		s.storeResult(d.index, result)
+	}
+}
@@ -0,0 +1,161 @@
+package simulator
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"temp +",
+		"(temp + hum",
+		"temp hum",
+		"temp + * hum",
+		"vpd(temp)",
+		"unknown(temp)",
+		"dli(a, b)",
+		"max(temp,)",
+	} {
+		if _, err := parseExpression(src); err == nil {
+			t.Errorf("parseExpression(%q) aceitou uma expressão inválida", src)
+		}
+	}
+}
+
+func TestParseExpressionPrecedence(t *testing.T) {
+	env := &exprEnv{values: map[string]float64{"a": 2, "b": 3, "c": 4, "a-b": 10}}
+	tests := []struct {
+		src  string
+		want float64
+		deps []string
+	}{
+		{"a + b * c", 14, []string{"a", "b", "c"}},
+		{"(a + b) * c", 20, []string{"a", "b", "c"}},
+		{"a - b - c", -5, []string{"a", "b", "c"}},
+		{"c / a / a", 1, []string{"c", "a"}},
+		{"-a ^ 2", -4, []string{"a"}},
+		{"a ^ b ^ 2", 512, []string{"a", "b"}},
+		{"a-b + 1", 11, []string{"a-b"}},
+		{"max(a, b, c) / 2", 2, []string{"a", "b", "c"}},
+		{"1e-3 * c", 0.004, []string{"c"}},
+		{"a + a", 4, []string{"a"}},
+	}
+	for _, tt := range tests {
+		expr, err := parseExpression(tt.src)
+		if err != nil {
+			t.Errorf("parseExpression(%q): %v", tt.src, err)
+			continue
+		}
+		if got := expr.root.eval(env); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q = %v, esperado %v", tt.src, got, tt.want)
+		}
+		if !reflect.DeepEqual(expr.deps, tt.deps) {
+			t.Errorf("%q: dependências %v, esperado %v", tt.src, expr.deps, tt.deps)
+		}
+	}
+}
+
+// derivedConfigs monta os sensores e o índice usados por newDerivedSensors
+func derivedConfigs(exprs map[string]string, ids ...string) ([]models.SensorConfig, map[string]int) {
+	configs := make([]models.SensorConfig, len(ids))
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		configs[i] = models.SensorConfig{ID: id, Type: models.Temperature, Expression: exprs[id]}
+		index[id] = i
+	}
+	return configs, index
+}
+
+func TestNewDerivedSensors(t *testing.T) {
+	tests := []struct {
+		name    string
+		exprs   map[string]string
+		ids     []string
+		order   []string // Ordem de cálculo esperada
+		wantErr string
+	}{
+		{
+			name:  "ordem topológica",
+			exprs: map[string]string{"d1": "d2 + 1", "d2": "t * 2", "d3": "d1 + d2"},
+			ids:   []string{"d3", "t", "d1", "d2"},
+			order: []string{"d2", "d1", "d3"},
+		},
+		{
+			name:    "dependência desconhecida",
+			exprs:   map[string]string{"d1": "x + 1"},
+			ids:     []string{"t", "d1"},
+			wantErr: `sensor desconhecido "x"`,
+		},
+		{
+			name:    "ciclo",
+			exprs:   map[string]string{"a": "b + 1", "b": "a + 1"},
+			ids:     []string{"a", "b"},
+			wantErr: "a -> b -> a",
+		},
+		{
+			name:    "autorreferência",
+			exprs:   map[string]string{"a": "a * 2"},
+			ids:     []string{"a"},
+			wantErr: "a -> a",
+		},
+		{
+			name:    "expressão inválida",
+			exprs:   map[string]string{"a": "t +"},
+			ids:     []string{"t", "a"},
+			wantErr: "sensor derivado a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, index := derivedConfigs(tt.exprs, tt.ids...)
+			derived, err := newDerivedSensors(configs, index)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var order []string
+			for _, d := range derived {
+				order = append(order, configs[d.index].ID)
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Fatalf("ordem = %v, esperado %v", order, tt.order)
+			}
+		})
+	}
+}
+
+func TestDerivedDropoutPropagation(t *testing.T) {
+	sensors := []models.SensorConfig{
+		{ID: "temp", Type: models.Temperature, Faults: []models.FaultConfig{{Type: models.FaultDropout}}},
+		{ID: "hum", Type: models.Humidity},
+		{ID: "from_temp", Type: models.Temperature, Expression: "temp + 1"},
+		{ID: "from_hum", Type: models.Humidity, Expression: "hum + 1"},
+	}
+	batches := collectSteps(t, newTestSimulator(t, sensors, WithSeed(5)), 5)
+
+	for _, batch := range batches {
+		seen := make(map[string]float64)
+		for _, reading := range batch {
+			seen[reading.SensorID] = reading.Value
+		}
+		if _, ok := seen["temp"]; ok {
+			t.Fatal("leitura de sensor em dropout foi entregue")
+		}
+		if _, ok := seen["from_temp"]; ok {
+			t.Fatal("sensor derivado de uma dependência em dropout gerou leitura")
+		}
+		if got, want := seen["from_hum"], seen["hum"]+1; got != want {
+			t.Fatalf("from_hum = %v, esperado %v", got, want)
+		}
+	}
+}
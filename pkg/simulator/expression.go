@@ -0,0 +1,375 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// exprEnv é o ambiente de avaliação de uma expressão em um ciclo da simulação
+type exprEnv struct {
+	values map[string]float64 // Valores dos sensores no ciclo atual
+	now    time.Time
+	step   time.Duration
+}
+
+// exprNode é um nó da árvore de uma expressão
+type exprNode interface {
+	eval(env *exprEnv) float64
+}
+
+type numberNode float64
+
+func (n numberNode) eval(*exprEnv) float64 { return float64(n) }
+
+type sensorNode string
+
+func (n sensorNode) eval(env *exprEnv) float64 { return env.values[string(n)] }
+
+type unaryNode struct {
+	x exprNode
+}
+
+func (n *unaryNode) eval(env *exprEnv) float64 { return -n.x.eval(env) }
+
+type binaryNode struct {
+	op   byte
+	l, r exprNode
+}
+
+func (n *binaryNode) eval(env *exprEnv) float64 {
+	l, r := n.l.eval(env), n.r.eval(env)
+	switch n.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	case '/':
+		return l / r
+	default:
+		return math.Pow(l, r)
+	}
+}
+
+type callNode struct {
+	fn   func(args []float64) float64
+	args []exprNode
+}
+
+func (n *callNode) eval(env *exprEnv) float64 {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(env)
+	}
+	return n.fn(args)
+}
+
+// dliNode acumula a integral diária de luz (mol/m²/d) de um sensor de PPFD (µmol/m²/s),
+// reiniciando à meia-noite do horário da simulação
+type dliNode struct {
+	x     exprNode
+	day   time.Time
+	total float64
+}
+
+func (n *dliNode) eval(env *exprEnv) float64 {
+	ppfd := n.x.eval(env)
+	y, m, d := env.now.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, env.now.Location())
+	if !day.Equal(n.day) {
+		n.day = day
+		n.total = 0
+	}
+	if ppfd > 0 && !math.IsInf(ppfd, 0) {
+		n.total += ppfd * env.step.Seconds() / 1e6
+	}
+	return n.total
+}
+
+// accumulators retorna as integrais diárias da expressão, na ordem em que aparecem
+func (e *expression) accumulators() []*dliNode {
+	var nodes []*dliNode
+	var walk func(n exprNode)
+	walk = func(n exprNode) {
+		switch n := n.(type) {
+		case *unaryNode:
+			walk(n.x)
+		case *binaryNode:
+			walk(n.l)
+			walk(n.r)
+		case *callNode:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		case *dliNode:
+			walk(n.x)
+			nodes = append(nodes, n)
+		}
+	}
+	walk(e.root)
+	return nodes
+}
+
+// exprFunc descreve uma função disponível nas expressões
+type exprFunc struct {
+	minArgs, maxArgs int
+	fn               func(args []float64) float64
+}
+
+// exprFuncs são as funções embutidas das expressões
+var exprFuncs = map[string]exprFunc{
+	"vpd":        {2, 3, vpd},
+	"dew_point":  {2, 2, func(a []float64) float64 { return dewPoint(a[0], a[1]) }},
+	"heat_index": {2, 2, func(a []float64) float64 { return heatIndex(a[0], a[1]) }},
+	"min":        {2, -1, func(a []float64) float64 { return reduce(a, math.Min) }},
+	"max":        {2, -1, func(a []float64) float64 { return reduce(a, math.Max) }},
+	"avg": {1, -1, func(a []float64) float64 {
+		return reduce(a, func(x, y float64) float64 { return x + y }) / float64(len(a))
+	}},
+	"abs":  {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt": {1, 1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"exp":  {1, 1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"log":  {1, 1, func(a []float64) float64 { return math.Log(a[0]) }},
+}
+
+// reduce combina os argumentos da esquerda para a direita
+func reduce(args []float64, f func(x, y float64) float64) float64 {
+	result := args[0]
+	for _, v := range args[1:] {
+		result = f(result, v)
+	}
+	return result
+}
+
+// vpd retorna o déficit de pressão de vapor (kPa) a partir da temperatura do ar (°C) e da
+// umidade relativa (%). Com um terceiro argumento, calcula o VPD da folha a partir da
+// temperatura da folha.
+func vpd(args []float64) float64 {
+	air := saturationVaporPressure(args[0]) * args[1] / 100
+	if len(args) == 3 {
+		return saturationVaporPressure(args[2]) - air
+	}
+	return saturationVaporPressure(args[0]) - air
+}
+
+// dewPoint retorna o ponto de orvalho (°C) pela fórmula de Magnus
+func dewPoint(temp, rh float64) float64 {
+	const a, b = 17.27, 237.7
+	gamma := math.Log(rh/100) + a*temp/(b+temp)
+	return b * gamma / (a - gamma)
+}
+
+// heatIndex retorna o índice de calor (°C) pela regressão de Rothfusz usada pelo NWS (NOAA)
+func heatIndex(temp, rh float64) float64 {
+	f := temp*9/5 + 32
+	hi := 0.5 * (f + 61 + (f-68)*1.2 + rh*0.094)
+	if (hi+f)/2 >= 80 {
+		hi = -42.379 + 2.04901523*f + 10.14333127*rh - 0.22475541*f*rh -
+			0.00683783*f*f - 0.05481717*rh*rh + 0.00122874*f*f*rh +
+			0.00085282*f*rh*rh - 0.00000199*f*f*rh*rh
+		if rh < 13 && f >= 80 && f <= 112 {
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(f-95))/17)
+		} else if rh > 85 && f >= 80 && f <= 87 {
+			hi += (rh - 85) / 10 * (87 - f) / 5
+		}
+	}
+	return (hi - 32) * 5 / 9
+}
+
+// expression é uma expressão compilada de um sensor derivado
+type expression struct {
+	root exprNode
+	deps []string // Sensores referenciados, sem repetição
+}
+
+// parseExpression compila uma expressão como "vpd(temp001, hum001)" ou "(temp001 + temp002) / 2".
+// Identificadores podem conter hífens (ex.: room001-temp001), por isso a subtração
+// deve ser escrita com espaços.
+func parseExpression(src string) (*expression, error) {
+	p := &exprParser{src: src}
+	p.next()
+	root, err := p.parseSum()
+	if err == nil && p.tok != "" {
+		err = fmt.Errorf("símbolo inesperado %q", p.tok)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expressão inválida %q: %w", src, err)
+	}
+	return &expression{root: root, deps: p.deps}, nil
+}
+
+// exprParser é um analisador descendente recursivo de expressões
+type exprParser struct {
+	src   string
+	pos   int
+	tok   string
+	ident bool // O símbolo atual é um identificador
+	deps  []string
+}
+
+// next avança para o próximo símbolo
+func (p *exprParser) next() {
+	var tok models.ExprToken
+	tok, p.pos = models.NextExprToken(p.src, p.pos)
+	p.tok, p.ident = tok.Text, tok.Ident
+}
+
+// expect consome o símbolo informado ou retorna erro
+func (p *exprParser) expect(tok string) error {
+	if p.tok != tok {
+		if p.tok == "" {
+			return fmt.Errorf("esperado %q, encontrado o fim da expressão", tok)
+		}
+		return fmt.Errorf("esperado %q, encontrado %q", tok, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+// parseSum analisa somas e subtrações
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "+" || p.tok == "-" {
+		op := p.tok[0]
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, l: left, r: right}
+	}
+	return left, nil
+}
+
+// parseProduct analisa multiplicações e divisões
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "*" || p.tok == "/" {
+		op := p.tok[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, l: left, r: right}
+	}
+	return left, nil
+}
+
+// parseUnary analisa o sinal negativo
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.tok == "-" {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{x: x}, nil
+	}
+	return p.parsePower()
+}
+
+// parsePower analisa a potenciação, associativa à direita
+func (p *exprParser) parsePower() (exprNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "^" {
+		return base, nil
+	}
+	p.next()
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: '^', l: base, r: exponent}, nil
+}
+
+// parsePrimary analisa números, sensores, chamadas de função e parênteses
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	switch {
+	case p.tok == "":
+		return nil, fmt.Errorf("fim inesperado da expressão")
+	case p.tok == "(":
+		p.next()
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case p.ident:
+		name := p.tok
+		p.next()
+		if p.tok == "(" {
+			return p.parseCall(name)
+		}
+		p.addDep(name)
+		return sensorNode(name), nil
+	case unicode.IsDigit(rune(p.tok[0])) || p.tok[0] == '.':
+		v, err := strconv.ParseFloat(p.tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q", p.tok)
+		}
+		p.next()
+		return numberNode(v), nil
+	}
+	return nil, fmt.Errorf("símbolo inesperado %q", p.tok)
+}
+
+// parseCall analisa os argumentos de uma chamada de função
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+	p.next()
+	var args []exprNode
+	for p.tok != ")" {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+
+	if name == "dli" {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("dli espera 1 argumento, recebeu %d", len(args))
+		}
+		return &dliNode{x: args[0]}, nil
+	}
+
+	f, ok := exprFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("função desconhecida %q", name)
+	}
+	if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+		return nil, fmt.Errorf("número de argumentos inválido para %s: %d", name, len(args))
+	}
+	return &callNode{fn: f.fn, args: args}, nil
+}
+
+// addDep registra um sensor referenciado pela expressão
+func (p *exprParser) addDep(id string) {
+	for _, dep := range p.deps {
+		if dep == id {
+			return
+		}
+	}
+	p.deps = append(p.deps, id)
+}
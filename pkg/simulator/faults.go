@@ -0,0 +1,238 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// fault é uma falha agendada para um sensor, com a janela de tempo já resolvida
+type fault struct {
+	id       int
+	sensorID string
+	config   models.FaultConfig
+	startAt  time.Time
+	endAt    time.Time // Zero significa falha sem término
+	held     float64   // Valor capturado no início de falhas stuck/flatline
+	heldSet  bool
+}
+
+// FaultStatus descreve uma falha e seu estado atual
+type FaultStatus struct {
+	ID       int                `json:"id"`
+	SensorID string             `json:"sensor_id"`
+	Config   models.FaultConfig `json:"config"`
+	StartAt  time.Time          `json:"start_at"`
+	EndAt    *time.Time         `json:"end_at,omitempty"`
+	Active   bool               `json:"active"`
+}
+
+// activeAt indica se a falha está ativa no horário informado
+func (f *fault) activeAt(now time.Time) bool {
+	if now.Before(f.startAt) {
+		return false
+	}
+	return f.endAt.IsZero() || now.Before(f.endAt)
+}
+
+// AddFault agenda uma falha para o sensor informado. O início da falha é relativo
+// ao horário atual da simulação. Retorna o identificador da falha.
+func (s *Simulator) AddFault(sensorID string, config models.FaultConfig) (int, error) {
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
+	return s.addFault(sensorID, config, s.clock.Now())
+}
+
+// addFault agenda uma falha com início relativo ao horário de referência
+func (s *Simulator) addFault(sensorID string, config models.FaultConfig, ref time.Time) (int, error) {
+	if !models.ValidFaultType(config.Type) {
+		return 0, fmt.Errorf("tipo de falha desconhecido: %q", config.Type)
+	}
+	if !s.hasSensor(sensorID) {
+		return 0, fmt.Errorf("sensor desconhecido: %s", sensorID)
+	}
+	if config.Probability < 0 || config.Probability > 1 {
+		return 0, fmt.Errorf("probabilidade deve estar entre 0 e 1, recebido %v", config.Probability)
+	}
+	if config.Duration < 0 {
+		return 0, fmt.Errorf("duração da falha não pode ser negativa")
+	}
+
+	f := &fault{
+		sensorID: sensorID,
+		config:   config,
+		startAt:  ref.Add(time.Duration(config.Start)),
+	}
+	if config.Duration > 0 {
+		f.endAt = f.startAt.Add(time.Duration(config.Duration))
+	}
+
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.nextFaultID++
+	f.id = s.nextFaultID
+	s.faults = append(s.faults, f)
+	return f.id, nil
+}
+
+// RemoveFault remove a falha com o identificador informado
+func (s *Simulator) RemoveFault(id int) bool {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+
+	for i, f := range s.faults {
+		if f.id == id {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// removeSensorFaults remove as falhas de um sensor removido da simulação
+func (s *Simulator) removeSensorFaults(sensorID string) {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+
+	kept := s.faults[:0]
+	for _, f := range s.faults {
+		if f.sensorID != sensorID {
+			kept = append(kept, f)
+		}
+	}
+	s.faults = kept
+}
+
+// ClearFaults remove todas as falhas
+func (s *Simulator) ClearFaults() {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.faults = nil
+}
+
+// Faults retorna todas as falhas agendadas e se estão ativas agora
+func (s *Simulator) Faults() []FaultStatus {
+	now := s.clock.Now()
+
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+
+	statuses := make([]FaultStatus, 0, len(s.faults))
+	for _, f := range s.faults {
+		status := FaultStatus{
+			ID:       f.id,
+			SensorID: f.sensorID,
+			Config:   f.config,
+			StartAt:  f.startAt,
+			Active:   f.activeAt(now),
+		}
+		if !f.endAt.IsZero() {
+			endAt := f.endAt
+			status.EndAt = &endAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
+}
+
+// faultsBySensor agrupa as falhas agendadas por sensor para o ciclo atual
+func (s *Simulator) faultsBySensor() map[string][]*fault {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+
+	if len(s.faults) == 0 {
+		return nil
+	}
+	bySensor := make(map[string][]*fault)
+	for _, f := range s.faults {
+		bySensor[f.sensorID] = append(bySensor[f.sensorID], f)
+	}
+	return bySensor
+}
+
+// applyFaults aplica as falhas do sensor que estão ativas ao valor medido. Retorna o valor
+// reportado, os tipos de falha ativos e se a leitura deve ser descartada.
+func applyFaults(rng *rand.Rand, config models.SensorConfig, value float64, now time.Time, faults []*fault) (float64, []models.FaultType, bool) {
+	var active []models.FaultType
+	dropped := false
+
+	for _, f := range faults {
+		if !f.activeAt(now) {
+			// Liberar o valor capturado quando a falha terminar
+			f.heldSet = false
+			continue
+		}
+		active = append(active, f.config.Type)
+
+		switch f.config.Type {
+		case models.FaultStuck:
+			value = f.hold(value)
+		case models.FaultFlatline:
+			magnitude := f.config.Magnitude
+			if magnitude == 0 {
+				magnitude = config.NoiseAmplitude * 0.05
+			}
+			value = f.hold(value) + uniformNoise(rng, magnitude)
+		case models.FaultSpike:
+			if rng.Float64() < probability(f.config, 0.05) {
+				magnitude := f.config.Magnitude
+				if magnitude == 0 {
+					magnitude = (config.MaxValue - config.MinValue) / 2
+				}
+				if rng.Float64() < 0.5 {
+					magnitude = -magnitude
+				}
+				value += magnitude
+			}
+		case models.FaultDropout:
+			if rng.Float64() < probability(f.config, 1) {
+				dropped = true
+			}
+		case models.FaultDrift:
+			value += f.config.Magnitude * now.Sub(f.startAt).Hours()
+		case models.FaultOffset:
+			value += f.config.Magnitude
+		case models.FaultSaturation:
+			if f.config.Value != nil {
+				value = *f.config.Value
+			} else {
+				value = config.MaxValue + (config.MaxValue-config.MinValue)*0.1
+			}
+		case models.FaultNaN:
+			if rng.Float64() < probability(f.config, 1) {
+				value = math.NaN()
+			}
+		case models.FaultInf:
+			if rng.Float64() < probability(f.config, 1) {
+				value = math.Inf(1)
+			}
+		}
+	}
+
+	return value, active, dropped
+}
+
+// hold retorna o valor travado da falha, capturando o valor atual na primeira chamada
+func (f *fault) hold(value float64) float64 {
+	if f.config.Value != nil {
+		return *f.config.Value
+	}
+	if !f.heldSet {
+		f.held = value
+		f.heldSet = true
+	}
+	return f.held
+}
+
+// probability retorna a probabilidade configurada ou o padrão do tipo de falha
+func probability(config models.FaultConfig, def float64) float64 {
+	if config.Probability == 0 {
+		return def
+	}
+	return config.Probability
+}
@@ -0,0 +1,158 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// GeneratorContext reúne o estado disponível para um gerador em cada ciclo
+type GeneratorContext struct {
+	Config    models.SensorConfig
+	LastValue float64       // Último valor gerado para o sensor
+	Drift     *float64      // Fator de drift do sensor (pode ser alterado pelo gerador)
+	Now       time.Time     // Horário da simulação
+	Elapsed   time.Duration // Tempo decorrido desde o início da simulação
+	Step      time.Duration // Tempo decorrido desde o ciclo anterior
+	Rand      *rand.Rand    // Gerador de números aleatórios do simulador
+
+	labels []models.AnomalyLabel // Eventos anômalos marcados pelo gerador na amostra
+}
+
+// Label marca a amostra atual com um evento anômalo gerado pelo modelo
+func (ctx *GeneratorContext) Label(label models.AnomalyLabel) {
+	ctx.labels = append(ctx.labels, label)
+}
+
+// Generator produz o próximo valor de um sensor
+type Generator interface {
+	Next(ctx *GeneratorContext) float64
+}
+
+// GeneratorFunc adapta uma função comum à interface Generator
+type GeneratorFunc func(ctx *GeneratorContext) float64
+
+// Next chama a função adaptada
+func (f GeneratorFunc) Next(ctx *GeneratorContext) float64 {
+	return f(ctx)
+}
+
+// absoluteGenerator é implementado pelos geradores cujo valor não parte do último valor
+// do sensor (LastValue), como os sinais periódicos. Alterar o último valor, como fazem os
+// eventos de cenário, não tem efeito sobre eles.
+type absoluteGenerator interface {
+	absolute()
+}
+
+// absoluteFunc adapta uma função comum a um gerador absoluto
+type absoluteFunc func(ctx *GeneratorContext) float64
+
+// Next chama a função adaptada
+func (f absoluteFunc) Next(ctx *GeneratorContext) float64 {
+	return f(ctx)
+}
+
+func (absoluteFunc) absolute() {}
+
+// followsLastValue indica se o gerador parte do último valor do sensor. Geradores
+// registrados com RegisterGenerator são considerados dependentes do último valor.
+func followsLastValue(generator Generator) bool {
+	_, absolute := generator.(absoluteGenerator)
+	return !absolute
+}
+
+// GeneratorFactory cria um gerador a partir da configuração do sensor e dos parâmetros
+type GeneratorFactory func(config models.SensorConfig, params map[string]float64) (Generator, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]GeneratorFactory)
+)
+
+// RegisterGenerator registra um gerador com o nome informado, substituindo um registro anterior
+func RegisterGenerator(name string, factory GeneratorFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// RegisteredGenerators retorna os nomes dos geradores registrados em ordem alfabética
+func RegisteredGenerators() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewGenerator cria o gerador configurado para o sensor.
+// Se o sensor não especificar um gerador, é usado o padrão do seu tipo.
+func NewGenerator(config models.SensorConfig) (Generator, error) {
+	name := defaultGeneratorName(config.Type)
+	var params map[string]float64
+	if config.Generator != nil {
+		if config.Generator.Name != "" {
+			name = config.Generator.Name
+		}
+		params = config.Generator.Params
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("gerador desconhecido %q para o sensor %s", name, config.ID)
+	}
+
+	generator, err := factory(config, params)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar gerador %q para o sensor %s: %w", name, config.ID, err)
+	}
+	return generator, nil
+}
+
+// defaultGeneratorName retorna o gerador padrão para cada tipo de sensor
+func defaultGeneratorName(sensorType models.SensorType) string {
+	switch sensorType {
+	case models.Temperature:
+		return "daily_temperature"
+	case models.Humidity:
+		return "daily_humidity"
+	case models.Light:
+		return "daily_light"
+	case models.Pressure:
+		return "daily_pressure"
+	case models.CO2, models.PPFD, models.WaterTemperature, models.LeafTemperature:
+		return "photoperiod"
+	case models.SubstrateMoisture:
+		return "dry_down"
+	case models.PH, models.EC:
+		return "dosing"
+	default:
+		return "random_walk"
+	}
+}
+
+// param retorna o parâmetro informado ou o valor padrão
+func param(params map[string]float64, name string, def float64) float64 {
+	if v, ok := params[name]; ok {
+		return v
+	}
+	return def
+}
+
+// uniformNoise retorna um ruído uniforme em [-amplitude, amplitude]
+func uniformNoise(rng *rand.Rand, amplitude float64) float64 {
+	if amplitude == 0 {
+		return 0
+	}
+	return (rng.Float64()*2 - 1) * amplitude
+}
@@ -0,0 +1,168 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func init() {
+	RegisterGenerator("constant", newConstantGenerator)
+	RegisterGenerator("random_walk", newRandomWalkGenerator)
+	RegisterGenerator("sine", newSineGenerator)
+	RegisterGenerator("sawtooth", newSawtoothGenerator)
+	RegisterGenerator("square", newSquareGenerator)
+	RegisterGenerator("step", newStepGenerator)
+	RegisterGenerator("ornstein_uhlenbeck", newOrnsteinUhlenbeckGenerator)
+}
+
+// midpoint retorna o ponto médio da faixa do sensor
+func midpoint(config models.SensorConfig) float64 {
+	return (config.MaxValue + config.MinValue) / 2
+}
+
+// newConstantGenerator cria um gerador de valor constante.
+// Parâmetros: value (padrão: meio da faixa), noise (padrão: 0)
+func newConstantGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	value := param(params, "value", midpoint(config))
+	noise := param(params, "noise", 0)
+
+	return absoluteFunc(func(ctx *GeneratorContext) float64 {
+		return value + uniformNoise(ctx.Rand, noise)
+	}), nil
+}
+
+// newRandomWalkGenerator cria um passeio aleatório a partir do último valor.
+// Parâmetros: step (padrão: noise_amplitude), drift (tendência por ciclo, padrão: 0)
+func newRandomWalkGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	step := param(params, "step", config.NoiseAmplitude)
+	drift := param(params, "drift", 0)
+
+	return GeneratorFunc(func(ctx *GeneratorContext) float64 {
+		return ctx.LastValue + uniformNoise(ctx.Rand, step) + drift
+	}), nil
+}
+
+// periodParam lê e valida o parâmetro de período em segundos
+func periodParam(params map[string]float64, def float64) (float64, error) {
+	period := param(params, "period", def)
+	if period <= 0 {
+		return 0, fmt.Errorf("o período deve ser positivo, recebido %v", period)
+	}
+	return period, nil
+}
+
+// phaseAt retorna a fração do período [0, 1) no instante atual da simulação
+func phaseAt(ctx *GeneratorContext, period, phase float64) float64 {
+	p := math.Mod(ctx.Elapsed.Seconds()/period+phase, 1)
+	if p < 0 {
+		p++
+	}
+	return p
+}
+
+// newSineGenerator cria uma senoide.
+// Parâmetros: offset (padrão: meio da faixa), amplitude (padrão: meia faixa),
+// period em segundos (padrão: 3600), phase em frações do período, noise (padrão: noise_amplitude)
+func newSineGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	offset := param(params, "offset", midpoint(config))
+	amplitude := param(params, "amplitude", (config.MaxValue-config.MinValue)/2)
+	phase := param(params, "phase", 0)
+	noise := param(params, "noise", config.NoiseAmplitude)
+	period, err := periodParam(params, 3600)
+	if err != nil {
+		return nil, err
+	}
+
+	return absoluteFunc(func(ctx *GeneratorContext) float64 {
+		return offset + amplitude*math.Sin(2*math.Pi*phaseAt(ctx, period, phase)) + uniformNoise(ctx.Rand, noise)
+	}), nil
+}
+
+// newSawtoothGenerator cria uma onda dente de serra que sobe de min a max a cada período.
+// Parâmetros: min, max (padrão: faixa do sensor), period em segundos (padrão: 3600), phase, noise
+func newSawtoothGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	low := param(params, "min", config.MinValue)
+	high := param(params, "max", config.MaxValue)
+	phase := param(params, "phase", 0)
+	noise := param(params, "noise", config.NoiseAmplitude)
+	period, err := periodParam(params, 3600)
+	if err != nil {
+		return nil, err
+	}
+
+	return absoluteFunc(func(ctx *GeneratorContext) float64 {
+		return low + (high-low)*phaseAt(ctx, period, phase) + uniformNoise(ctx.Rand, noise)
+	}), nil
+}
+
+// newSquareGenerator cria uma onda quadrada alternando entre low e high.
+// Parâmetros: low, high (padrão: faixa do sensor), period em segundos (padrão: 3600),
+// duty (fração do período em high, padrão: 0.5), phase, noise
+func newSquareGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	low := param(params, "low", config.MinValue)
+	high := param(params, "high", config.MaxValue)
+	duty := param(params, "duty", 0.5)
+	phase := param(params, "phase", 0)
+	noise := param(params, "noise", config.NoiseAmplitude)
+	period, err := periodParam(params, 3600)
+	if err != nil {
+		return nil, err
+	}
+	if duty < 0 || duty > 1 {
+		return nil, fmt.Errorf("duty deve estar entre 0 e 1, recebido %v", duty)
+	}
+
+	return absoluteFunc(func(ctx *GeneratorContext) float64 {
+		value := low
+		if phaseAt(ctx, period, phase) < duty {
+			value = high
+		}
+		return value + uniformNoise(ctx.Rand, noise)
+	}), nil
+}
+
+// newStepGenerator cria um degrau que muda de before para after após o instante at.
+// Parâmetros: before (padrão: mínimo), after (padrão: máximo), at em segundos desde o início (padrão: 60), noise
+func newStepGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	before := param(params, "before", config.MinValue)
+	after := param(params, "after", config.MaxValue)
+	at := param(params, "at", 60)
+	noise := param(params, "noise", config.NoiseAmplitude)
+
+	return absoluteFunc(func(ctx *GeneratorContext) float64 {
+		value := before
+		if ctx.Elapsed.Seconds() >= at {
+			value = after
+		}
+		return value + uniformNoise(ctx.Rand, noise)
+	}), nil
+}
+
+// newOrnsteinUhlenbeckGenerator cria um processo de Ornstein-Uhlenbeck com reversão à média.
+// Parâmetros: mean (padrão: meio da faixa), theta (taxa de reversão por segundo, padrão: 0.1),
+// sigma (volatilidade por raiz de segundo, padrão: noise_amplitude)
+func newOrnsteinUhlenbeckGenerator(config models.SensorConfig, params map[string]float64) (Generator, error) {
+	mean := param(params, "mean", midpoint(config))
+	theta := param(params, "theta", 0.1)
+	sigma := param(params, "sigma", config.NoiseAmplitude)
+	if theta < 0 || sigma < 0 {
+		return nil, fmt.Errorf("theta e sigma não podem ser negativos")
+	}
+
+	return GeneratorFunc(func(ctx *GeneratorContext) float64 {
+		dt := ctx.Step.Seconds()
+		if dt <= 0 {
+			return ctx.LastValue
+		}
+		// Solução exata do processo no intervalo, estável para qualquer theta*dt
+		// (o passo de Euler diverge quando theta*dt > 2)
+		decay := math.Exp(-theta * dt)
+		stddev := sigma * math.Sqrt(dt)
+		if theta > 0 {
+			stddev = sigma * math.Sqrt((1-decay*decay)/(2*theta))
+		}
+		return mean + (ctx.LastValue-mean)*decay + stddev*ctx.Rand.NormFloat64()
+	}), nil
+}
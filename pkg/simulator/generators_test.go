@@ -0,0 +1,42 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func TestOrnsteinUhlenbeckLargeStep(t *testing.T) {
+	config := models.SensorConfig{
+		ID:       "ou",
+		Type:     models.Temperature,
+		MinValue: 0,
+		MaxValue: 40,
+		Generator: &models.GeneratorConfig{
+			Name:   "ornstein_uhlenbeck",
+			Params: map[string]float64{"mean": 20, "theta": 0.5, "sigma": 0.1},
+		},
+	}
+	generator, err := NewGenerator(config)
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+
+	// theta*dt = 30: o passo de Euler oscilaria e divergiria; a solução exata converge à média
+	rng := rand.New(rand.NewSource(1))
+	value := 40.0
+	for i := 0; i < 100; i++ {
+		value = generator.Next(&GeneratorContext{Config: config, LastValue: value, Step: time.Minute, Rand: rng})
+		if math.IsNaN(value) || math.Abs(value-20) > 1 && i > 0 {
+			t.Fatalf("passo %d: valor %v longe da média 20", i, value)
+		}
+	}
+
+	// Com passo nulo o valor não muda
+	if got := generator.Next(&GeneratorContext{Config: config, LastValue: 25, Rand: rng}); got != 25 {
+		t.Fatalf("passo nulo alterou o valor para %v", got)
+	}
+}
@@ -0,0 +1,401 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// GrowRoomConfig descreve uma sala de cultivo simulada por um modelo físico acoplado:
+// o fotoperíodo liga as luzes, as luzes aquecem a sala, a temperatura altera a umidade
+// relativa e o HVAC e o desumidificador trazem os valores de volta aos alvos.
+type GrowRoomConfig struct {
+	ID    string `json:"id"`
+	Stage string `json:"stage"` // "veg" (18/6) ou "flower" (12/12)
+
+	LightsOnHour   float64 `json:"lights_on_hour"`  // Hora em que as luzes acendem (padrão: 6)
+	LightHours     float64 `json:"light_hours"`     // Horas de luz; sobrepõe o padrão do estágio
+	LightIntensity float64 `json:"light_intensity"` // Luminosidade com as luzes acesas (lux, padrão: 40000)
+	PPFD           float64 `json:"ppfd"`            // PPFD com as luzes acesas (µmol/m²/s, padrão: 800)
+	RampMinutes    float64 `json:"ramp_minutes"`    // Rampa de nascer/pôr das luzes (padrão: 15)
+	LampHeat       float64 `json:"lamp_heat"`       // Aquecimento das luzes (°C/h, padrão: 3)
+	AmbientTemp    float64 `json:"ambient_temp"`    // Temperatura externa (°C, padrão: 20)
+	AmbientRH      float64 `json:"ambient_rh"`      // Umidade relativa externa (%, padrão: 50)
+	Insulation     float64 `json:"insulation"`      // Troca térmica com o exterior (1/h, padrão: 0.3)
+	AirExchange    float64 `json:"air_exchange"`    // Troca de vapor com o exterior (1/h, padrão: 0.5)
+	Transpiration  float64 `json:"transpiration"`   // Vapor liberado pelas plantas com luz (kPa/h, padrão: 0.8)
+	DayTemp        float64 `json:"day_temp"`        // Setpoint de temperatura com luz (°C, padrão: 26)
+	NightTemp      float64 `json:"night_temp"`      // Setpoint de temperatura sem luz (°C, padrão: 21)
+	TempBand       float64 `json:"temp_band"`       // Histerese do HVAC (°C, padrão: 0.5)
+	CoolingRate    float64 `json:"cooling_rate"`    // Capacidade de resfriamento (°C/h, padrão: 6)
+	HeatingRate    float64 `json:"heating_rate"`    // Capacidade de aquecimento (°C/h, padrão: 4)
+	TargetRH       float64 `json:"target_rh"`       // Alvo do desumidificador (%, padrão: 65 veg / 50 flower)
+	RHBand         float64 `json:"rh_band"`         // Histerese do desumidificador (%, padrão: 3)
+	DehumidifyRate float64 `json:"dehumidify_rate"` // Capacidade do desumidificador (kPa/h, padrão: 1)
+
+	// Controle externo: desliga o fotoperíodo, o HVAC e o desumidificador automáticos,
+	// deixando a sala apenas sob o comando dos atuadores virtuais
+	ExternalControl bool    `json:"external_control"`
+	HumidifyRate    float64 `json:"humidify_rate"`   // Capacidade do umidificador (kPa/h, padrão: 1)
+	CO2Rate         float64 `json:"co2_rate"`        // Injeção da válvula de CO2 (ppm/h, padrão: 1500)
+	CO2Uptake       float64 `json:"co2_uptake"`      // Consumo de CO2 pelas plantas com luz (ppm/h, padrão: 60)
+	AmbientCO2      float64 `json:"ambient_co2"`     // CO2 externo (ppm, padrão: 420)
+	IrrigationRate  float64 `json:"irrigation_rate"` // Umedecimento do substrato pela bomba (%/h, padrão: 60)
+	DryingRate      float64 `json:"drying_rate"`     // Secagem do substrato com luz (%/h, padrão: 2)
+	LeafCooling     float64 `json:"leaf_cooling"`    // Folha abaixo do ar pela transpiração com luz (°C, padrão: 2)
+	WaterExchange   float64 `json:"water_exchange"`  // Troca térmica do reservatório com o ar (1/h, padrão: 0.5)
+}
+
+// withDefaults preenche os parâmetros não informados com valores típicos de uma sala de cultivo
+func (c GrowRoomConfig) withDefaults() GrowRoomConfig {
+	setDefault := func(v *float64, def float64) {
+		if *v == 0 {
+			*v = def
+		}
+	}
+
+	if c.Stage == "" {
+		c.Stage = "veg"
+	}
+	stageHours, stageRH := 18.0, 65.0
+	if c.Stage == "flower" {
+		stageHours, stageRH = 12, 50
+	}
+
+	setDefault(&c.LightsOnHour, 6)
+	setDefault(&c.LightHours, stageHours)
+	setDefault(&c.LightIntensity, 40000)
+	setDefault(&c.PPFD, 800)
+	setDefault(&c.RampMinutes, 15)
+	setDefault(&c.LampHeat, 3)
+	setDefault(&c.AmbientTemp, 20)
+	setDefault(&c.AmbientRH, 50)
+	setDefault(&c.Insulation, 0.3)
+	setDefault(&c.AirExchange, 0.5)
+	setDefault(&c.Transpiration, 0.8)
+	setDefault(&c.DayTemp, 26)
+	setDefault(&c.NightTemp, 21)
+	setDefault(&c.TempBand, 0.5)
+	setDefault(&c.CoolingRate, 6)
+	setDefault(&c.HeatingRate, 4)
+	setDefault(&c.TargetRH, stageRH)
+	setDefault(&c.RHBand, 3)
+	setDefault(&c.DehumidifyRate, 1)
+	setDefault(&c.HumidifyRate, 1)
+	setDefault(&c.CO2Rate, 1500)
+	setDefault(&c.CO2Uptake, 60)
+	setDefault(&c.AmbientCO2, 420)
+	setDefault(&c.IrrigationRate, 60)
+	setDefault(&c.DryingRate, 2)
+	setDefault(&c.LeafCooling, 2)
+	setDefault(&c.WaterExchange, 0.5)
+	return c
+}
+
+// HVACMode indica o modo de operação do HVAC
+type HVACMode string
+
+const (
+	HVACOff     HVACMode = "off"
+	HVACCooling HVACMode = "cooling"
+	HVACHeating HVACMode = "heating"
+)
+
+// GrowRoomState é o estado físico atual de uma sala de cultivo
+type GrowRoomState struct {
+	ID            string   `json:"id"`
+	Temperature   float64  `json:"temperature"`    // °C
+	Humidity      float64  `json:"humidity"`       // %
+	VaporPressure float64  `json:"vapor_pressure"` // kPa
+	Light         float64  `json:"light"`          // lux
+	LightsOn      bool     `json:"lights_on"`
+	HVAC          HVACMode `json:"hvac"`
+	Dehumidifier  bool     `json:"dehumidifier"`
+	CO2           float64  `json:"co2"`                // ppm
+	Substrate     float64  `json:"substrate_moisture"` // %
+	PPFD          float64  `json:"ppfd"`               // µmol/m²/s
+	LeafTemp      float64  `json:"leaf_temperature"`   // °C
+	WaterTemp     float64  `json:"water_temperature"`  // °C
+}
+
+// growRoom é o modelo físico de uma sala de cultivo
+type growRoom struct {
+	config GrowRoomConfig
+	state  GrowRoomState
+}
+
+// maxRoomSubstep limita o passo de integração para manter o modelo estável em tempo acelerado
+const maxRoomSubstep = 10 * time.Second
+
+// newGrowRoom cria o modelo de uma sala partindo do equilíbrio com os setpoints
+func newGrowRoom(config GrowRoomConfig) (*growRoom, error) {
+	if config.ID == "" {
+		return nil, fmt.Errorf("sala de cultivo sem id")
+	}
+	if config.Stage != "" && config.Stage != "veg" && config.Stage != "flower" {
+		return nil, fmt.Errorf("estágio inválido %q na sala %s (use veg ou flower)", config.Stage, config.ID)
+	}
+	config = config.withDefaults()
+	if config.LightHours > 24 {
+		return nil, fmt.Errorf("horas de luz inválidas na sala %s: %v", config.ID, config.LightHours)
+	}
+
+	room := &growRoom{config: config}
+	room.state = GrowRoomState{
+		ID:            config.ID,
+		Temperature:   config.NightTemp,
+		VaporPressure: saturationVaporPressure(config.NightTemp) * config.TargetRH / 100,
+		HVAC:          HVACOff,
+		CO2:           config.AmbientCO2,
+		Substrate:     50,
+		LeafTemp:      config.NightTemp,
+		WaterTemp:     config.NightTemp,
+	}
+	room.state.Humidity = config.TargetRH
+	return room, nil
+}
+
+// saturationVaporPressure retorna a pressão de saturação do vapor (kPa) pela fórmula de Tetens
+func saturationVaporPressure(temp float64) float64 {
+	return 0.6108 * math.Exp(17.27*temp/(temp+237.3))
+}
+
+// lightFraction retorna a fração de luz (0 a 1) no horário informado, com rampas de acendimento
+func (r *growRoom) lightFraction(now time.Time) float64 {
+	return photoperiodFraction(now, r.config.LightsOnHour, r.config.LightHours, r.config.RampMinutes)
+}
+
+// step avança o modelo físico pelo intervalo informado, com os níveis de acionamento
+// dos atuadores da sala
+func (r *growRoom) step(now time.Time, dt time.Duration, levels map[models.ActuatorType]float64) {
+	if dt <= 0 {
+		light := r.lights(now, levels)
+		r.state.Light = r.config.LightIntensity * light
+		r.state.PPFD = r.config.PPFD * light
+		r.state.LeafTemp = r.state.Temperature - r.config.LeafCooling*light
+		r.state.LightsOn = light > 0
+		return
+	}
+
+	// Integrar em subpassos curtos, do instante anterior até o atual
+	start := now.Add(-dt)
+	for elapsed := time.Duration(0); elapsed < dt; {
+		h := dt - elapsed
+		if h > maxRoomSubstep {
+			h = maxRoomSubstep
+		}
+		elapsed += h
+		r.integrate(start.Add(elapsed), h.Hours(), levels)
+	}
+}
+
+// lights retorna a fração de luz considerando o fotoperíodo e o atuador de iluminação
+func (r *growRoom) lights(now time.Time, levels map[models.ActuatorType]float64) float64 {
+	commanded := math.Min(1, levels[models.Lights])
+	if r.config.ExternalControl {
+		return commanded
+	}
+	// Iluminação comandada complementa o fotoperíodo
+	return math.Max(r.lightFraction(now), commanded)
+}
+
+// integrate aplica um passo de Euler de h horas ao estado da sala
+func (r *growRoom) integrate(now time.Time, h float64, levels map[models.ActuatorType]float64) {
+	c := r.config
+	st := &r.state
+
+	light := r.lights(now, levels)
+	st.Light = c.LightIntensity * light
+	st.LightsOn = light > 0
+
+	// Controles automáticos, desligados quando a sala está sob controle externo
+	if c.ExternalControl {
+		st.HVAC = HVACOff
+		st.Dehumidifier = false
+	} else {
+		// Setpoint de temperatura segue o fotoperíodo
+		setpoint := c.NightTemp
+		if st.LightsOn {
+			setpoint = c.DayTemp
+		}
+
+		// Controle do HVAC com histerese (gera ciclos liga/desliga como em uma sala real)
+		switch {
+		case st.Temperature > setpoint+c.TempBand:
+			st.HVAC = HVACCooling
+		case st.Temperature < setpoint-c.TempBand:
+			st.HVAC = HVACHeating
+		case st.HVAC == HVACCooling && st.Temperature <= setpoint,
+			st.HVAC == HVACHeating && st.Temperature >= setpoint:
+			st.HVAC = HVACOff
+		}
+
+		// Controle do desumidificador com histerese
+		if st.Humidity > c.TargetRH+c.RHBand {
+			st.Dehumidifier = true
+		} else if st.Dehumidifier && st.Humidity <= c.TargetRH {
+			st.Dehumidifier = false
+		}
+	}
+
+	// Balanço térmico: luzes aquecem, a sala troca calor com o exterior e o HVAC,
+	// o aquecedor e o resfriador compensam
+	dT := c.LampHeat*light - c.Insulation*(st.Temperature-c.AmbientTemp)
+	switch st.HVAC {
+	case HVACCooling:
+		dT -= c.CoolingRate
+	case HVACHeating:
+		dT += c.HeatingRate
+	}
+	dT += c.HeatingRate*levels[models.Heater] - c.CoolingRate*levels[models.Chiller]
+	st.Temperature += dT * h
+
+	// Balanço de vapor: transpiração (maior com luz), troca de ar, umidificador e desumidificador
+	ambientVapor := saturationVaporPressure(c.AmbientTemp) * c.AmbientRH / 100
+	transpiration := c.Transpiration * (0.25 + 0.75*light)
+	dE := transpiration - c.AirExchange*(st.VaporPressure-ambientVapor)
+	if st.Dehumidifier {
+		dE -= c.DehumidifyRate
+	}
+	dE += c.HumidifyRate*levels[models.Humidifier] - c.DehumidifyRate*levels[models.Dehumidifier]
+	st.VaporPressure = math.Max(0.05, st.VaporPressure+dE*h)
+
+	// CO2: injeção pela válvula, consumo pelas plantas com luz e troca de ar
+	dC := c.CO2Rate*levels[models.CO2Valve] - c.CO2Uptake*light - c.AirExchange*(st.CO2-c.AmbientCO2)
+	st.CO2 = math.Max(0, st.CO2+dC*h)
+
+	// Substrato: irrigação pela bomba e secagem por evapotranspiração
+	dS := c.IrrigationRate*levels[models.IrrigationPump] - c.DryingRate*(0.3+0.7*light)
+	st.Substrate = math.Min(100, math.Max(0, st.Substrate+dS*h))
+
+	// Reservatório da solução nutritiva acompanha a temperatura do ar lentamente
+	st.WaterTemp += c.WaterExchange * (st.Temperature - st.WaterTemp) * h
+
+	// PPFD acompanha a iluminação e a folha fica abaixo do ar quando transpira com luz
+	st.PPFD = c.PPFD * light
+	st.LeafTemp = st.Temperature - c.LeafCooling*light
+
+	// Umidade relativa depende da temperatura: a mesma quantidade de vapor em ar mais
+	// quente resulta em umidade relativa menor
+	saturation := saturationVaporPressure(st.Temperature)
+	st.VaporPressure = math.Min(st.VaporPressure, saturation)
+	st.Humidity = 100 * st.VaporPressure / saturation
+}
+
+// value retorna a grandeza da sala correspondente ao tipo de sensor
+func (r *growRoom) value(sensorType models.SensorType) (float64, bool) {
+	switch sensorType {
+	case models.Temperature:
+		return r.state.Temperature, true
+	case models.Humidity:
+		return r.state.Humidity, true
+	case models.Light:
+		return r.state.Light, true
+	case models.CO2:
+		return r.state.CO2, true
+	case models.PPFD:
+		return r.state.PPFD, true
+	case models.SubstrateMoisture:
+		return r.state.Substrate, true
+	case models.LeafTemperature:
+		return r.state.LeafTemp, true
+	case models.WaterTemperature:
+		return r.state.WaterTemp, true
+	}
+	return 0, false
+}
+
+// growRoomSensor é o gerador de um sensor instalado em uma sala de cultivo
+type growRoomSensor struct {
+	room *growRoom
+}
+
+// Next retorna a grandeza da sala com o ruído de medição do sensor
+func (g *growRoomSensor) Next(ctx *GeneratorContext) float64 {
+	value, _ := g.room.value(ctx.Config.Type)
+	return value + uniformNoise(ctx.Rand, ctx.Config.NoiseAmplitude)
+}
+
+// O valor vem da sala, e não do último valor do sensor
+func (g *growRoomSensor) absolute() {}
+
+// WithGrowRooms define as salas de cultivo disponíveis para sensores com o gerador "grow_room"
+func WithGrowRooms(rooms []GrowRoomConfig) Option {
+	return func(s *Simulator) {
+		s.growRoomConfigs = rooms
+	}
+}
+
+// newGrowRooms cria os modelos das salas de cultivo configuradas
+func newGrowRooms(configs []GrowRoomConfig) (map[string]*growRoom, error) {
+	rooms := make(map[string]*growRoom, len(configs))
+	for _, config := range configs {
+		if _, exists := rooms[config.ID]; exists {
+			return nil, fmt.Errorf("sala de cultivo duplicada: %s", config.ID)
+		}
+		room, err := newGrowRoom(config)
+		if err != nil {
+			return nil, err
+		}
+		rooms[config.ID] = room
+	}
+	return rooms, nil
+}
+
+// newSensorGenerator cria o gerador do sensor. Os geradores que dependem do estado do
+// simulador ("grow_room", "solar", "solar_temperature" e "weather") são ligados aqui aos
+// seus modelos.
+func (s *Simulator) newSensorGenerator(config models.SensorConfig) (Generator, error) {
+	name := ""
+	var params map[string]float64
+	if config.Generator != nil {
+		name, params = config.Generator.Name, config.Generator.Params
+	} else if s.sun != nil && config.Type == models.Light {
+		name = "solar"
+	} else if s.weather != nil && weatherTypes[config.Type] {
+		name = "weather"
+	}
+
+	var generator Generator
+	var err error
+	switch name {
+	case "grow_room":
+		room, ok := s.growRooms[config.Generator.Room]
+		if !ok {
+			return nil, fmt.Errorf("sala de cultivo desconhecida %q para o sensor %s", config.Generator.Room, config.ID)
+		}
+		if _, ok := room.value(config.Type); !ok {
+			return nil, fmt.Errorf("a sala de cultivo não fornece valores do tipo %s (sensor %s)", config.Type, config.ID)
+		}
+		return &growRoomSensor{room: room}, nil
+	case "solar", "solar_temperature":
+		if s.sun == nil {
+			return nil, fmt.Errorf("o gerador %q exige a configuração do local (sensor %s)", name, config.ID)
+		}
+		if name == "solar" {
+			generator, err = newSolarGenerator(s.sun, config, params)
+		} else {
+			generator, err = newSolarTemperatureGenerator(s.sun, s.weather, config, params)
+		}
+	case "weather":
+		if s.weather == nil {
+			return nil, fmt.Errorf("o gerador %q exige a configuração do tempo (sensor %s)", name, config.ID)
+		}
+		generator, err = newWeatherGenerator(s.weather, config, params)
+	default:
+		return NewGenerator(config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar gerador %q para o sensor %s: %w", name, config.ID, err)
+	}
+	return generator, nil
+}
+
+// stepGrowRooms avança o modelo físico de todas as salas até o horário atual
+func (s *Simulator) stepGrowRooms(now time.Time, dt time.Duration, roomLevels map[string]map[models.ActuatorType]float64) {
+	for id, room := range s.growRooms {
+		room.step(now, dt, roomLevels[id])
+	}
+}
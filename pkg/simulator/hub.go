@@ -0,0 +1,292 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// OverflowPolicy define o que acontece quando a fila de um assinante está cheia
+type OverflowPolicy string
+
+const (
+	DropNewest OverflowPolicy = "drop_newest" // Descarta o ciclo que chegou (padrão)
+	DropOldest OverflowPolicy = "drop_oldest" // Descarta o ciclo mais antigo da fila
+	Block      OverflowPolicy = "block"       // Aguarda espaço na fila, atrasando a simulação
+)
+
+// SubscriberConfig configura a fila de um assinante das leituras
+type SubscriberConfig struct {
+	Buffer    int            `json:"buffer"`             // Ciclos enfileirados (0 = entrega síncrona no ciclo)
+	BatchSize int            `json:"batch_size"`         // Máximo de leituras por chamada (0 = ciclo completo)
+	Overflow  OverflowPolicy `json:"overflow,omitempty"` // Política com a fila cheia (padrão: drop_newest)
+
+	// Recebe também os registros das amostras descartadas (Dropped), que levam apenas os rótulos
+	IncludeDropped bool `json:"include_dropped,omitempty"`
+}
+
+// SubscriberStatus descreve a fila de um assinante
+type SubscriberStatus struct {
+	Name      string         `json:"name"`
+	Buffer    int            `json:"buffer"`
+	Overflow  OverflowPolicy `json:"overflow"`
+	Queued    int            `json:"queued"`
+	Delivered int64          `json:"delivered"` // Ciclos entregues
+	Dropped   int            `json:"dropped"`   // Ciclos descartados por fila cheia
+}
+
+// Subscription é a assinatura de um destino às leituras do simulador. Cada assinatura
+// assíncrona tem a sua fila e goroutine, de modo que um destino lento não atrasa os demais.
+type Subscription struct {
+	hub      *hub
+	name     string
+	config   SubscriberConfig
+	callback func([]models.SensorReading)
+
+	mu        sync.Mutex
+	cond      *sync.Cond // Sinaliza ciclos na fila, espaço livre e encerramento
+	queue     [][]models.SensorReading
+	closed    bool
+	delivered int64
+	dropped   int
+	done      chan struct{} // Fechado quando a goroutine de entrega termina
+}
+
+// hub distribui as leituras de cada ciclo aos assinantes
+type hub struct {
+	mu   sync.RWMutex
+	subs []*Subscription
+}
+
+// WithAsyncDelivery faz o simulador entregar as leituras ao callback de NewSimulator de forma
+// assíncrona, com uma fila de até buffer ciclos. Quando a fila está cheia, o ciclo mais novo é
+// descartado. batchSize limita o número de leituras por chamada do callback (0 = sem limite).
+func WithAsyncDelivery(buffer, batchSize int) Option {
+	return func(s *Simulator) {
+		s.callbackConfig = SubscriberConfig{Buffer: buffer, BatchSize: batchSize, Overflow: DropNewest}
+	}
+}
+
+// Subscribe registra um destino para as leituras de cada ciclo. Com buffer zero o callback é
+// chamado no próprio ciclo; caso contrário, em uma goroutine própria, com a política de
+// descarte configurada.
+func (s *Simulator) Subscribe(name string, config SubscriberConfig, callback func([]models.SensorReading)) (*Subscription, error) {
+	if callback == nil {
+		return nil, fmt.Errorf("assinante %q sem callback", name)
+	}
+	if config.Buffer < 0 || config.BatchSize < 0 {
+		return nil, fmt.Errorf("buffer e batch_size não podem ser negativos (assinante %q)", name)
+	}
+	switch config.Overflow {
+	case "":
+		config.Overflow = DropNewest
+	case DropNewest, DropOldest, Block:
+	default:
+		return nil, fmt.Errorf("política de descarte desconhecida %q (assinante %q)", config.Overflow, name)
+	}
+
+	sub := &Subscription{
+		hub:      &s.hub,
+		name:     name,
+		config:   config,
+		callback: callback,
+		done:     make(chan struct{}),
+	}
+	sub.cond = sync.NewCond(&sub.mu)
+	if config.Buffer > 0 {
+		go sub.run()
+	} else {
+		close(sub.done)
+	}
+
+	s.hub.mu.Lock()
+	s.hub.subs = append(s.hub.subs, sub)
+	s.hub.mu.Unlock()
+	return sub, nil
+}
+
+// Subscribers retorna o estado da fila de cada assinante
+func (s *Simulator) Subscribers() []SubscriberStatus {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+
+	statuses := make([]SubscriberStatus, 0, len(s.hub.subs))
+	for _, sub := range s.hub.subs {
+		statuses = append(statuses, sub.Status())
+	}
+	return statuses
+}
+
+// DroppedBatches retorna quantos ciclos foram descartados por filas de assinantes cheias
+func (s *Simulator) DroppedBatches() int {
+	total := 0
+	for _, status := range s.Subscribers() {
+		total += status.Dropped
+	}
+	return total
+}
+
+// Publish entrega um lote de leituras a todos os assinantes. O simulador publica as leituras
+// de cada ciclo; fontes externas, como a reprodução de CSV, usam o mesmo caminho. Assinantes
+// com a política block deixam de aguardar espaço na fila quando ctx é cancelado. Os registros
+// de amostras descartadas só chegam aos assinantes com IncludeDropped.
+func (s *Simulator) Publish(ctx context.Context, readings []models.SensorReading) {
+	s.hub.mu.RLock()
+	subs := append([]*Subscription(nil), s.hub.subs...)
+	s.hub.mu.RUnlock()
+
+	var delivered []models.SensorReading // Leituras sem os registros descartados, montadas uma vez
+	filtered := false
+	for _, sub := range subs {
+		if sub.config.IncludeDropped {
+			sub.publish(ctx, readings)
+			continue
+		}
+		if !filtered {
+			delivered = withoutDropped(readings)
+			filtered = true
+		}
+		sub.publish(ctx, delivered)
+	}
+}
+
+// withoutDropped retorna as leituras sem os registros de amostras descartadas
+func withoutDropped(readings []models.SensorReading) []models.SensorReading {
+	for i, reading := range readings {
+		if reading.Dropped {
+			kept := append([]models.SensorReading(nil), readings[:i]...)
+			for _, reading := range readings[i+1:] {
+				if !reading.Dropped {
+					kept = append(kept, reading)
+				}
+			}
+			return kept
+		}
+	}
+	return readings
+}
+
+// publish entrega ou enfileira as leituras de um ciclo
+func (sub *Subscription) publish(ctx context.Context, readings []models.SensorReading) {
+	if sub.config.Buffer == 0 {
+		sub.mu.Lock()
+		closed := sub.closed
+		sub.mu.Unlock()
+		if !closed {
+			sub.send(readings)
+		}
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	for !sub.closed && len(sub.queue) >= sub.config.Buffer {
+		switch sub.config.Overflow {
+		case Block:
+			// Uma fila que não esvazia não pode travar o ciclo nem o encerramento
+			if ctx.Err() != nil {
+				sub.drop()
+				return
+			}
+			stop := context.AfterFunc(ctx, func() {
+				sub.mu.Lock()
+				sub.cond.Broadcast()
+				sub.mu.Unlock()
+			})
+			sub.cond.Wait()
+			stop()
+		case DropOldest:
+			sub.queue = sub.queue[1:]
+			sub.drop()
+		default:
+			sub.drop()
+			return
+		}
+	}
+	if sub.closed {
+		return
+	}
+	sub.queue = append(sub.queue, readings)
+	sub.cond.Broadcast()
+}
+
+// drop contabiliza um ciclo descartado; deve ser chamado com mu travado
+func (sub *Subscription) drop() {
+	sub.dropped++
+	// Registrar apenas periodicamente para não inundar o log
+	if sub.dropped == 1 || sub.dropped%1000 == 0 {
+		log.Printf("Aviso: fila do assinante %q cheia, ciclo descartado (%d descartados)", sub.name, sub.dropped)
+	}
+}
+
+// run consome a fila do assinante até o cancelamento da assinatura
+func (sub *Subscription) run() {
+	defer close(sub.done)
+	for {
+		sub.mu.Lock()
+		for len(sub.queue) == 0 && !sub.closed {
+			sub.cond.Wait()
+		}
+		if len(sub.queue) == 0 {
+			sub.mu.Unlock()
+			return
+		}
+		readings := sub.queue[0]
+		sub.queue = sub.queue[1:]
+		sub.cond.Broadcast()
+		sub.mu.Unlock()
+
+		sub.send(readings)
+	}
+}
+
+// send chama o callback com as leituras de um ciclo, em lotes
+func (sub *Subscription) send(readings []models.SensorReading) {
+	if size := sub.config.BatchSize; size > 0 {
+		for start := 0; start < len(readings); start += size {
+			sub.callback(readings[start:min(start+size, len(readings))])
+		}
+	} else {
+		sub.callback(readings)
+	}
+
+	sub.mu.Lock()
+	sub.delivered++
+	sub.mu.Unlock()
+}
+
+// Status retorna o estado da fila do assinante
+func (sub *Subscription) Status() SubscriberStatus {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return SubscriberStatus{
+		Name:      sub.name,
+		Buffer:    sub.config.Buffer,
+		Overflow:  sub.config.Overflow,
+		Queued:    len(sub.queue),
+		Delivered: sub.delivered,
+		Dropped:   sub.dropped,
+	}
+}
+
+// Unsubscribe cancela a assinatura. Os ciclos já enfileirados são entregues antes do retorno.
+func (sub *Subscription) Unsubscribe() {
+	h := sub.hub
+	h.mu.Lock()
+	for i, other := range h.subs {
+		if other == sub {
+			h.subs = append(h.subs[:i:i], h.subs[i+1:]...)
+			break
+		}
+	}
+	h.mu.Unlock()
+
+	sub.mu.Lock()
+	sub.closed = true
+	sub.cond.Broadcast()
+	sub.mu.Unlock()
+	<-sub.done
+}
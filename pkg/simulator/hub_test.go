@@ -0,0 +1,158 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// gatedSubscriber é um assinante lento: cada entrega aguarda uma liberação do teste
+type gatedSubscriber struct {
+	entered chan time.Time // Timestamp de cada ciclo ao entrar no callback
+	release chan struct{}
+}
+
+func newGatedSubscriber() *gatedSubscriber {
+	return &gatedSubscriber{entered: make(chan time.Time, 16), release: make(chan struct{})}
+}
+
+func (g *gatedSubscriber) callback(readings []models.SensorReading) {
+	g.entered <- readings[0].Timestamp
+	<-g.release
+}
+
+// cycle retorna o número do ciclo (a partir de 1) de um timestamp dos testes
+func cycle(timestamp time.Time) int {
+	return int(timestamp.Sub(testStart) / time.Second)
+}
+
+func TestSubscriberOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy    OverflowPolicy
+		dropped   int
+		delivered []int // Ciclos entregues, em ordem
+	}{
+		{DropNewest, 2, []int{1, 2, 3}},
+		{DropOldest, 2, []int{1, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			sim := newTestSimulator(t, testSensors(1), WithSeed(1))
+			slow := newGatedSubscriber()
+			sub, err := sim.Subscribe("slow", SubscriberConfig{Buffer: 2, Overflow: tt.policy}, slow.callback)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// O ciclo 1 fica preso no callback; os ciclos 2 e 3 enchem a fila e os
+			// ciclos 4 e 5 encontram a fila cheia
+			collectSteps(t, sim, 1)
+			var delivered []int
+			delivered = append(delivered, cycle(<-slow.entered))
+			collectSteps(t, sim, 4)
+
+			if got := sub.Status().Dropped; got != tt.dropped {
+				t.Fatalf("descartados = %d, esperado %d", got, tt.dropped)
+			}
+			if got := sim.DroppedBatches(); got != tt.dropped {
+				t.Fatalf("DroppedBatches = %d, esperado %d", got, tt.dropped)
+			}
+
+			close(slow.release)
+			sub.Unsubscribe()
+			close(slow.entered)
+			for timestamp := range slow.entered {
+				delivered = append(delivered, cycle(timestamp))
+			}
+			if len(delivered) != len(tt.delivered) {
+				t.Fatalf("ciclos entregues = %v, esperado %v", delivered, tt.delivered)
+			}
+			for i := range delivered {
+				if delivered[i] != tt.delivered[i] {
+					t.Fatalf("ciclos entregues = %v, esperado %v", delivered, tt.delivered)
+				}
+			}
+		})
+	}
+}
+
+func TestSubscriberBlockBackpressure(t *testing.T) {
+	sim := newTestSimulator(t, testSensors(1), WithSeed(1))
+	slow := newGatedSubscriber()
+	sub, err := sim.Subscribe("slow", SubscriberConfig{Buffer: 1, Overflow: Block}, slow.callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ciclo 1 preso no callback, ciclo 2 na fila: o ciclo 3 precisa aguardar espaço
+	collectSteps(t, sim, 1)
+	<-slow.entered
+	collectSteps(t, sim, 1)
+
+	stepped := make(chan struct{})
+	go func() {
+		sim.Step()
+		close(stepped)
+	}()
+	select {
+	case <-stepped:
+		t.Fatal("Step não foi bloqueado com a fila cheia")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Liberar o ciclo 1 abre espaço para o ciclo 3
+	slow.release <- struct{}{}
+	select {
+	case <-stepped:
+	case <-time.After(time.Second):
+		t.Fatal("Step continuou bloqueado após a fila ter espaço")
+	}
+
+	if got := sim.DroppedBatches(); got != 0 {
+		t.Fatalf("DroppedBatches = %d com a política block", got)
+	}
+	close(slow.release)
+	sub.Unsubscribe()
+	if status := sub.Status(); status.Dropped != 0 || status.Delivered != 3 {
+		t.Fatalf("entregues = %d, descartados = %d; esperado 3 e 0", status.Delivered, status.Dropped)
+	}
+}
+
+func TestStopWithBlockedSubscriber(t *testing.T) {
+	sim := newTestSimulator(t, testSensors(1), WithSeed(1))
+	slow := newGatedSubscriber()
+	sub, err := sim.Subscribe("stuck", SubscriberConfig{Buffer: 1, Overflow: Block}, slow.callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		close(slow.release)
+		sub.Unsubscribe()
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- sim.Run(context.Background(), time.Millisecond) }()
+
+	// Com um ciclo preso no callback e a fila cheia, o laço fica aguardando espaço
+	<-slow.entered
+	time.Sleep(20 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		sim.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop travou com um assinante bloqueado")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run retornou %v após Stop", err)
+	}
+	if sub.Status().Dropped == 0 {
+		t.Fatal("o ciclo interrompido pelo encerramento não foi contado como descartado")
+	}
+}
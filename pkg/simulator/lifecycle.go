@@ -0,0 +1,232 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// SimulationState indica o estado do ciclo de vida do simulador
+type SimulationState string
+
+const (
+	SimulationIdle    SimulationState = "idle"    // Criado, ainda não executado
+	SimulationRunning SimulationState = "running" // Gerando leituras a cada intervalo
+	SimulationPaused  SimulationState = "paused"  // Em execução, sem gerar leituras
+	SimulationStopped SimulationState = "stopped" // Execução encerrada
+)
+
+var (
+	// ErrAlreadyRunning indica que o simulador já está em execução
+	ErrAlreadyRunning = errors.New("o simulador já está em execução")
+	// ErrNotRunning indica que a operação exige o simulador em execução
+	ErrNotRunning = errors.New("o simulador não está em execução")
+)
+
+// SimulationStatus descreve o estado de execução do simulador
+type SimulationStatus struct {
+	State    SimulationState `json:"state"`
+	Interval models.Duration `json:"interval"`
+	Ticks    int64           `json:"ticks"`
+	Now      time.Time       `json:"now"`
+}
+
+// WithInterval define o intervalo usado por Step antes da primeira execução com Run
+func WithInterval(interval time.Duration) Option {
+	return func(s *Simulator) {
+		s.interval = interval
+	}
+}
+
+// Run executa a simulação, gerando leituras a cada intervalo, até o contexto ser
+// cancelado ou Stop ser chamado. Retorna o erro do contexto no primeiro caso e nil no segundo.
+func (s *Simulator) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("o intervalo da simulação deve ser positivo, recebido %v", interval)
+	}
+
+	s.lifecycleMu.Lock()
+	if s.state == SimulationRunning || s.state == SimulationPaused {
+		s.lifecycleMu.Unlock()
+		return ErrAlreadyRunning
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.state = SimulationRunning
+	s.interval = interval
+	s.cancel = cancel
+	s.done = done
+	s.lifecycleMu.Unlock()
+
+	defer func() {
+		cancel()
+		s.lifecycleMu.Lock()
+		if s.state == SimulationPaused {
+			s.resumeClock()
+		}
+		s.state = SimulationStopped
+		s.cancel = nil
+		s.lifecycleMu.Unlock()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-runCtx.Done():
+			// Parada por Stop não é erro; cancelamento do contexto externo é
+			return ctx.Err()
+		case <-ticker.C:
+			if s.State() == SimulationPaused {
+				continue
+			}
+			// Relógios manuais avançam exatamente um intervalo por ciclo
+			if manual, ok := s.clock.(*ManualClock); ok {
+				manual.Advance(interval)
+			}
+			s.tick(runCtx)
+		}
+	}
+}
+
+// Start inicia o simulador em segundo plano; use Stop para encerrá-lo
+func (s *Simulator) Start(interval time.Duration) {
+	go func() {
+		if err := s.Run(context.Background(), interval); err != nil {
+			log.Printf("Erro na execução do simulador: %v", err)
+		}
+	}()
+}
+
+// Stop encerra a execução e aguarda o ciclo em andamento terminar
+func (s *Simulator) Stop() {
+	s.lifecycleMu.Lock()
+	cancel, done := s.cancel, s.done
+	s.lifecycleMu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Pause suspende a geração de leituras. O relógio virtual é congelado, de modo que o
+// tempo simulado não avança durante a pausa.
+func (s *Simulator) Pause() error {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+
+	if s.state != SimulationRunning {
+		return ErrNotRunning
+	}
+	if clock, ok := s.clock.(*VirtualClock); ok {
+		clock.Pause()
+	}
+	s.state = SimulationPaused
+	return nil
+}
+
+// Resume retoma a geração de leituras após Pause
+func (s *Simulator) Resume() error {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+
+	if s.state != SimulationPaused {
+		return fmt.Errorf("o simulador não está pausado")
+	}
+	s.resumeClock()
+	s.state = SimulationRunning
+	return nil
+}
+
+// resumeClock retoma o relógio após uma pausa. Relógios que não podem ser congelados
+// avançaram durante a pausa; o intervalo é descartado em vez de gerar as amostras perdidas.
+// Deve ser chamado com lifecycleMu travado.
+func (s *Simulator) resumeClock() {
+	switch clock := s.clock.(type) {
+	case *VirtualClock:
+		clock.Resume()
+	case *ManualClock:
+	default:
+		s.skipTo(s.clock.Now())
+	}
+}
+
+// skipTo avança o estado da simulação até now sem gerar leituras
+func (s *Simulator) skipTo(now time.Time) {
+	s.sensorsMu.Lock()
+	defer s.sensorsMu.Unlock()
+	s.skipToLocked(now)
+}
+
+// skipToLocked é skipTo com sensorsMu já travado
+func (s *Simulator) skipToLocked(now time.Time) {
+	s.lastTick = now
+	for i, config := range s.configs {
+		s.schedules[i].skip(config, now, s.rng)
+	}
+	s.scenarioMu.Lock()
+	if s.scenario != nil {
+		s.scenario.lastTick = now
+	}
+	s.scenarioMu.Unlock()
+}
+
+// Step executa um único ciclo com a simulação parada ou pausada. Relógios manuais e
+// virtuais congelados avançam um intervalo de simulação antes do ciclo.
+func (s *Simulator) Step() error {
+	s.lifecycleMu.Lock()
+	if s.state == SimulationRunning {
+		s.lifecycleMu.Unlock()
+		return fmt.Errorf("pause o simulador antes de avançar um ciclo")
+	}
+	interval := s.interval
+	switch clock := s.clock.(type) {
+	case *ManualClock:
+		clock.Advance(interval)
+	case *VirtualClock:
+		if clock.Paused() {
+			clock.Advance(time.Duration(float64(interval) * clock.Speed()))
+		}
+	}
+	s.lifecycleMu.Unlock()
+
+	s.tick(context.Background())
+	return nil
+}
+
+// tick executa um ciclo de simulação; ciclos do laço de execução e de Step não se sobrepõem.
+// O cancelamento de ctx libera a entrega a assinantes bloqueados.
+func (s *Simulator) tick(ctx context.Context) {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+
+	s.simulateReadings(ctx)
+	s.lifecycleMu.Lock()
+	s.ticks++
+	s.lifecycleMu.Unlock()
+}
+
+// State retorna o estado do ciclo de vida do simulador
+func (s *Simulator) State() SimulationState {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	return s.state
+}
+
+// Status retorna o estado de execução do simulador
+func (s *Simulator) Status() SimulationStatus {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	return SimulationStatus{
+		State:    s.state,
+		Interval: models.Duration(s.interval),
+		Ticks:    s.ticks,
+		Now:      s.clock.Now(),
+	}
+}
@@ -0,0 +1,125 @@
+package simulator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startVirtual inicia o simulador com relógio virtual em segundo plano e aguarda o estado
+// running; o retorno de Run é enviado ao canal
+func startVirtual(t *testing.T, interval time.Duration) (*Simulator, *VirtualClock, <-chan error) {
+	t.Helper()
+	clock, err := NewVirtualClock(testStart, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim, err := NewSimulator(testSensors(16), nil, WithSeed(1), WithClock(clock), WithWorkers(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- sim.Run(context.Background(), interval) }()
+	for sim.State() != SimulationRunning {
+		time.Sleep(time.Millisecond)
+	}
+	return sim, clock, done
+}
+
+func TestLifecycleConcurrentControl(t *testing.T) {
+	sim, _, done := startVirtual(t, time.Millisecond)
+
+	// Comandos concorrentes podem falhar conforme o estado (ex.: Step em execução), mas não
+	// podem causar corrida nem deixar o simulador em estado inconsistente
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, op := range []func(){
+		func() { sim.Pause() },
+		func() { sim.Resume() },
+		func() { sim.Step() },
+		func() { sim.Status() },
+		func() { sim.GetReadings() },
+		func() { sim.Now() },
+	} {
+		wg.Add(1)
+		go func(op func()) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					op()
+				}
+			}
+		}(op)
+	}
+	time.Sleep(200 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	if state := sim.State(); state != SimulationRunning && state != SimulationPaused {
+		t.Fatalf("estado %q após comandos concorrentes", state)
+	}
+	sim.Stop()
+	if err := <-done; err != nil {
+		t.Fatalf("Run retornou %v após Stop", err)
+	}
+	if state := sim.State(); state != SimulationStopped {
+		t.Fatalf("estado %q após Stop, esperado stopped", state)
+	}
+	if sim.Status().Ticks == 0 {
+		t.Fatal("nenhum ciclo executado")
+	}
+}
+
+func TestPauseFreezesVirtualClock(t *testing.T) {
+	sim, clock, done := startVirtual(t, 5*time.Millisecond)
+	defer func() {
+		sim.Stop()
+		<-done
+	}()
+
+	if err := sim.Step(); err == nil {
+		t.Fatal("Step aceito com o simulador em execução")
+	}
+	if err := sim.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if !clock.Paused() {
+		t.Fatal("relógio virtual não foi congelado por Pause")
+	}
+
+	// Aguardar um eventual ciclo em andamento antes de medir
+	time.Sleep(20 * time.Millisecond)
+	frozen, ticks := sim.Now(), sim.Status().Ticks
+	time.Sleep(50 * time.Millisecond)
+	if now := sim.Now(); !now.Equal(frozen) {
+		t.Fatalf("relógio avançou %v durante a pausa", now.Sub(frozen))
+	}
+	if got := sim.Status().Ticks; got != ticks {
+		t.Fatalf("%d ciclos executados durante a pausa", got-ticks)
+	}
+
+	// Step avança exatamente um intervalo simulado (intervalo × velocidade)
+	if err := sim.Step(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sim.Now().Sub(frozen), time.Duration(float64(5*time.Millisecond)*60); got != want {
+		t.Fatalf("Step avançou %v, esperado %v", got, want)
+	}
+	if got := sim.Status().Ticks; got != ticks+1 {
+		t.Fatalf("Step executou %d ciclos, esperado 1", got-ticks)
+	}
+
+	if err := sim.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	resumed := sim.Now()
+	time.Sleep(20 * time.Millisecond)
+	if !sim.Now().After(resumed) {
+		t.Fatal("relógio não voltou a avançar após Resume")
+	}
+}
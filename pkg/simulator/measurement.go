@@ -0,0 +1,141 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// measurementChain aplica a cadeia de medição de um sensor: resposta de primeira ordem,
+// histerese, erro de exatidão, conversão A/D e resolução do valor reportado
+type measurementChain struct {
+	config models.MeasurementConfig
+
+	gain, offset   float64 // Erro de exatidão sorteado na criação (calibração do sensor)
+	fullScale      float64
+	adcMin, adcMax float64
+	lsb            float64 // Passo do conversor A/D
+
+	lagged  float64 // Saída do elemento sensor (após a resposta de primeira ordem)
+	output  float64 // Saída após a histerese
+	started bool
+}
+
+// newMeasurementChain cria a cadeia de medição do sensor, ou nil se não configurada.
+// O erro de exatidão é sorteado dentro da classe de exatidão informada.
+func newMeasurementChain(sensor models.SensorConfig, rng *rand.Rand) (*measurementChain, error) {
+	if sensor.Measurement == nil {
+		return nil, nil
+	}
+	c := *sensor.Measurement
+
+	if c.TimeConstant < 0 || c.Hysteresis < 0 || c.AccuracyReading < 0 || c.AccuracyFullScale < 0 || c.Resolution < 0 {
+		return nil, fmt.Errorf("parâmetros da cadeia de medição não podem ser negativos (sensor %s)", sensor.ID)
+	}
+	if c.ADCBits < 0 || c.ADCBits > 32 {
+		return nil, fmt.Errorf("adc_bits deve estar entre 1 e 32, recebido %d (sensor %s)", c.ADCBits, sensor.ID)
+	}
+
+	m := &measurementChain{config: c, fullScale: sensor.MaxValue - sensor.MinValue, adcMin: c.ADCMin, adcMax: c.ADCMax}
+	if m.adcMin == 0 && m.adcMax == 0 {
+		m.adcMin, m.adcMax = sensor.MinValue, sensor.MaxValue
+	}
+	if c.ADCBits > 0 {
+		if m.adcMax <= m.adcMin {
+			return nil, fmt.Errorf("faixa do conversor A/D inválida [%v, %v] (sensor %s)", m.adcMin, m.adcMax, sensor.ID)
+		}
+		m.lsb = (m.adcMax - m.adcMin) / (math.Exp2(float64(c.ADCBits)) - 1)
+	}
+
+	m.gain = uniformNoise(rng, c.AccuracyReading/100)
+	m.offset = uniformNoise(rng, c.AccuracyFullScale/100*m.fullScale)
+	return m, nil
+}
+
+// MeasurementState é o estado de uma cadeia de medição: calibração sorteada e saída atual
+type MeasurementState struct {
+	Gain    float64   `json:"gain"`
+	Offset  float64   `json:"offset"`
+	Lagged  jsonFloat `json:"lagged"`
+	Output  jsonFloat `json:"output"`
+	Started bool      `json:"started"`
+}
+
+// state retorna o estado da cadeia de medição
+func (m *measurementChain) state() MeasurementState {
+	return MeasurementState{
+		Gain:    m.gain,
+		Offset:  m.offset,
+		Lagged:  jsonFloat(m.lagged),
+		Output:  jsonFloat(m.output),
+		Started: m.started,
+	}
+}
+
+// restore retoma a cadeia de medição a partir de um estado salvo
+func (m *measurementChain) restore(state MeasurementState) {
+	m.gain, m.offset = state.Gain, state.Offset
+	m.lagged, m.output = float64(state.Lagged), float64(state.Output)
+	m.started = state.Started
+}
+
+// apply converte o valor físico no valor medido. dt é o tempo desde a amostra anterior.
+func (m *measurementChain) apply(value float64, dt time.Duration) float64 {
+	c := m.config
+	if !m.started {
+		m.lagged, m.output = value, value
+		m.started = true
+	}
+
+	// Resposta de primeira ordem do elemento sensor
+	if tau := time.Duration(c.TimeConstant); tau > 0 && dt > 0 {
+		m.lagged += (value - m.lagged) * (1 - math.Exp(-dt.Seconds()/tau.Seconds()))
+	} else {
+		m.lagged = value
+	}
+
+	// Histerese (folga): a saída só acompanha a entrada quando ela sai da banda
+	if half := c.Hysteresis / 2; half > 0 {
+		if m.lagged > m.output+half {
+			m.output = m.lagged - half
+		} else if m.lagged < m.output-half {
+			m.output = m.lagged + half
+		}
+	} else {
+		m.output = m.lagged
+	}
+
+	// Erro de exatidão: ganho (% da leitura) e offset (% do fundo de escala)
+	measured := m.output*(1+m.gain) + m.offset
+
+	// Conversão A/D: satura na faixa do conversor e quantiza no passo de um bit
+	if m.lsb > 0 {
+		code := math.Round((measured - m.adcMin) / m.lsb)
+		code = math.Max(0, math.Min(code, math.Exp2(float64(c.ADCBits))-1))
+		measured = m.adcMin + code*m.lsb
+	}
+
+	// Resolução do valor reportado
+	if c.Resolution > 0 {
+		measured = roundToStep(measured, c.Resolution)
+	}
+	return measured
+}
+
+// roundToStep arredonda o valor para o múltiplo mais próximo do passo, eliminando
+// resíduos de ponto flutuante (ex.: 21.300000000000001 com passo 0.1)
+func roundToStep(value, step float64) float64 {
+	rounded := math.Round(value/step) * step
+
+	decimals := 0
+	if text := strconv.FormatFloat(step, 'f', -1, 64); strings.Contains(text, ".") {
+		decimals = len(text) - strings.Index(text, ".") - 1
+	}
+	scale := math.Pow10(decimals)
+	return math.Round(rounded*scale) / scale
+}
@@ -0,0 +1,100 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// maxSamplesPerTick limita as amostras de um sensor em um único ciclo. Se o relógio saltar
+// (pausa longa, aceleração), as amostras mais antigas são descartadas.
+const maxSamplesPerTick = 1000
+
+// sampleSchedule é o agendamento de amostragem de um sensor
+type sampleSchedule struct {
+	nominal time.Time // Próximo instante nominal, sem jitter
+	next    time.Time // Próximo instante de amostragem, com jitter
+	last    time.Time // Instante da última amostra
+}
+
+// validateSampling verifica os parâmetros de amostragem do sensor
+func validateSampling(config models.SensorConfig) error {
+	interval := time.Duration(config.SampleInterval)
+	offset := time.Duration(config.SampleOffset)
+	jitter := time.Duration(config.SampleJitter)
+
+	if interval < 0 || offset < 0 || jitter < 0 {
+		return fmt.Errorf("parâmetros de amostragem não podem ser negativos (sensor %s)", config.ID)
+	}
+	if interval == 0 && (offset > 0 || jitter > 0) {
+		return fmt.Errorf("sample_offset e sample_jitter exigem sample_interval (sensor %s)", config.ID)
+	}
+	// Jitter menor que meio intervalo mantém as amostras de um sensor em ordem
+	if jitter*2 >= interval && interval > 0 {
+		return fmt.Errorf("sample_jitter deve ser menor que metade de sample_interval (sensor %s)", config.ID)
+	}
+	return nil
+}
+
+// newSampleSchedule cria o agendamento do sensor a partir do início da simulação
+func newSampleSchedule(config models.SensorConfig, start time.Time, rng *rand.Rand) sampleSchedule {
+	sch := sampleSchedule{last: start}
+	interval := time.Duration(config.SampleInterval)
+	if interval == 0 {
+		return sch
+	}
+
+	offset := time.Duration(config.SampleOffset) % interval
+	if offset == 0 {
+		offset = interval
+	}
+	sch.nominal = start.Add(offset)
+	sch.next = sch.nominal.Add(sampleJitter(config, rng))
+	return sch
+}
+
+// sampleJitter sorteia o deslocamento da próxima amostra em [-jitter, jitter]
+func sampleJitter(config models.SensorConfig, rng *rand.Rand) time.Duration {
+	jitter := time.Duration(config.SampleJitter)
+	if jitter == 0 {
+		return 0
+	}
+	return time.Duration((rng.Float64()*2 - 1) * float64(jitter))
+}
+
+// due retorna os instantes de amostragem até now e avança o agendamento.
+// Sensores sem intervalo próprio são amostrados uma vez por ciclo, em now.
+func (sch *sampleSchedule) due(config models.SensorConfig, now time.Time, rng *rand.Rand) []time.Time {
+	interval := time.Duration(config.SampleInterval)
+	if interval == 0 {
+		return []time.Time{now}
+	}
+
+	// Pular amostras que excedem o limite do ciclo
+	if behind := now.Sub(sch.nominal) / interval; behind > maxSamplesPerTick {
+		sch.nominal = sch.nominal.Add((behind - maxSamplesPerTick) * interval)
+		sch.next = sch.nominal.Add(sampleJitter(config, rng))
+	}
+
+	var times []time.Time
+	for !sch.next.After(now) {
+		times = append(times, sch.next)
+		sch.nominal = sch.nominal.Add(interval)
+		sch.next = sch.nominal.Add(sampleJitter(config, rng))
+	}
+	return times
+}
+
+// skip descarta as amostras agendadas até now, sem gerá-las
+func (sch *sampleSchedule) skip(config models.SensorConfig, now time.Time, rng *rand.Rand) {
+	sch.last = now
+	interval := time.Duration(config.SampleInterval)
+	if interval == 0 || sch.next.After(now) {
+		return
+	}
+	behind := now.Sub(sch.nominal)/interval + 1
+	sch.nominal = sch.nominal.Add(behind * interval)
+	sch.next = sch.nominal.Add(sampleJitter(config, rng))
+}
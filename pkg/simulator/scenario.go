@@ -0,0 +1,289 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScenarioAction define a ação executada por um evento de cenário
+type ScenarioAction string
+
+const (
+	ActionSet   ScenarioAction = "set"   // Define o valor do sensor imediatamente
+	ActionRamp  ScenarioAction = "ramp"  // Leva o valor do sensor até o alvo ao longo de duration
+	ActionDrift ScenarioAction = "drift" // Define o fator de drift do sensor
+	ActionFault ScenarioAction = "fault" // Injeta uma falha no sensor
+)
+
+// ScenarioEvent é um evento agendado em relação ao início do cenário
+type ScenarioEvent struct {
+	At       models.Duration     `json:"at"`
+	SensorID string              `json:"sensor_id"`
+	Action   ScenarioAction      `json:"action"`
+	Value    float64             `json:"value,omitempty"`
+	Duration models.Duration     `json:"duration,omitempty"`
+	Fault    *models.FaultConfig `json:"fault,omitempty"`
+}
+
+// Scenario descreve um roteiro de teste com eventos temporizados
+type Scenario struct {
+	Name   string          `json:"name"`
+	Events []ScenarioEvent `json:"events"`
+}
+
+// ScenarioState indica o estado de execução do cenário
+type ScenarioState string
+
+const (
+	ScenarioIdle     ScenarioState = "idle"
+	ScenarioRunning  ScenarioState = "running"
+	ScenarioPaused   ScenarioState = "paused"
+	ScenarioFinished ScenarioState = "finished"
+)
+
+// ScenarioStatus descreve o progresso do cenário carregado
+type ScenarioStatus struct {
+	Name        string          `json:"name"`
+	State       ScenarioState   `json:"state"`
+	Elapsed     models.Duration `json:"elapsed"`
+	EventsFired int             `json:"events_fired"`
+	EventsTotal int             `json:"events_total"`
+	NextEvent   *ScenarioEvent  `json:"next_event,omitempty"`
+}
+
+// scenarioRun guarda o estado de execução de um cenário
+type scenarioRun struct {
+	scenario *Scenario
+	state    ScenarioState
+	elapsed  time.Duration // Tempo de cenário decorrido (tempo simulado, sem pausas)
+	lastTick time.Time
+	next     int // Índice do próximo evento a disparar
+	ramps    []*ramp
+}
+
+// ramp é uma rampa linear em andamento no valor de um sensor
+type ramp struct {
+	sensorID string
+	from     float64
+	to       float64
+	start    time.Duration
+	duration time.Duration
+}
+
+// ParseScenario interpreta um cenário em JSON ou YAML
+func ParseScenario(data []byte) (*Scenario, error) {
+	// YAML é um superconjunto de JSON; converter para JSON reaproveita as regras de desserialização
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar cenário: %w", err)
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao interpretar cenário: %w", err)
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(jsonData, &scenario); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar cenário: %w", err)
+	}
+	return &scenario, nil
+}
+
+// LoadScenarioFile lê um cenário de um arquivo JSON ou YAML
+func LoadScenarioFile(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de cenário: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// LoadScenario valida e carrega um cenário, substituindo o anterior. O cenário fica
+// parado até StartScenario ser chamado.
+func (s *Simulator) LoadScenario(scenario *Scenario) error {
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
+
+	if err := s.validateScenario(scenario); err != nil {
+		return err
+	}
+
+	// Ordenar eventos pelo instante, preservando a ordem do arquivo em empates
+	events := make([]ScenarioEvent, len(scenario.Events))
+	copy(events, scenario.Events)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+
+	s.scenarioMu.Lock()
+	defer s.scenarioMu.Unlock()
+	s.scenario = &scenarioRun{
+		scenario: &Scenario{Name: scenario.Name, Events: events},
+		state:    ScenarioIdle,
+	}
+	return nil
+}
+
+// validateScenario verifica os sensores e as ações dos eventos; deve ser chamado com sensorsMu travado
+func (s *Simulator) validateScenario(scenario *Scenario) error {
+	for i, event := range scenario.Events {
+		if !s.hasSensor(event.SensorID) {
+			return fmt.Errorf("evento %d: sensor desconhecido: %s", i, event.SensorID)
+		}
+		switch event.Action {
+		case ActionSet, ActionRamp, ActionDrift:
+			generator, ok := s.generators[event.SensorID]
+			if !ok {
+				return fmt.Errorf("evento %d: o sensor derivado %s não pode ser alterado diretamente", i, event.SensorID)
+			}
+			// Essas ações alteram o último valor e o drift, ignorados por geradores absolutos
+			if !followsLastValue(generator) {
+				return fmt.Errorf("evento %d: a ação %s não tem efeito no gerador do sensor %s, que não parte do último valor", i, event.Action, event.SensorID)
+			}
+		case ActionFault:
+			if event.Fault == nil || !models.ValidFaultType(event.Fault.Type) {
+				return fmt.Errorf("evento %d: falha ausente ou inválida", i)
+			}
+		default:
+			return fmt.Errorf("evento %d: ação desconhecida: %q", i, event.Action)
+		}
+	}
+	return nil
+}
+
+// StartScenario inicia o cenário carregado, ou o retoma se estiver pausado
+func (s *Simulator) StartScenario() error {
+	s.scenarioMu.Lock()
+	defer s.scenarioMu.Unlock()
+
+	run := s.scenario
+	if run == nil {
+		return fmt.Errorf("nenhum cenário carregado")
+	}
+	if run.state != ScenarioPaused {
+		run.elapsed = 0
+		run.next = 0
+		run.ramps = nil
+	}
+	run.state = ScenarioRunning
+	run.lastTick = s.clock.Now()
+	return nil
+}
+
+// PauseScenario pausa o cenário em execução
+func (s *Simulator) PauseScenario() error {
+	s.scenarioMu.Lock()
+	defer s.scenarioMu.Unlock()
+
+	if s.scenario == nil || s.scenario.state != ScenarioRunning {
+		return fmt.Errorf("nenhum cenário em execução")
+	}
+	s.scenario.state = ScenarioPaused
+	return nil
+}
+
+// ScenarioStatus retorna o progresso do cenário carregado (nil se não houver cenário)
+func (s *Simulator) ScenarioStatus() *ScenarioStatus {
+	s.scenarioMu.Lock()
+	defer s.scenarioMu.Unlock()
+
+	run := s.scenario
+	if run == nil {
+		return nil
+	}
+	status := &ScenarioStatus{
+		Name:        run.scenario.Name,
+		State:       run.state,
+		Elapsed:     models.Duration(run.elapsed),
+		EventsFired: run.next,
+		EventsTotal: len(run.scenario.Events),
+	}
+	if run.next < len(run.scenario.Events) {
+		next := run.scenario.Events[run.next]
+		status.NextEvent = &next
+	}
+	return status
+}
+
+// advanceScenario avança o cenário até o horário informado, disparando os eventos
+// vencidos e atualizando as rampas. Chamado a cada ciclo antes da geração de valores.
+func (s *Simulator) advanceScenario(now time.Time) {
+	s.scenarioMu.Lock()
+	defer s.scenarioMu.Unlock()
+
+	run := s.scenario
+	if run == nil || run.state != ScenarioRunning {
+		return
+	}
+	run.elapsed += now.Sub(run.lastTick)
+	run.lastTick = now
+
+	// Disparar eventos vencidos
+	for run.next < len(run.scenario.Events) && time.Duration(run.scenario.Events[run.next].At) <= run.elapsed {
+		s.fireEvent(run, run.scenario.Events[run.next])
+		run.next++
+	}
+
+	// Atualizar rampas em andamento
+	active := run.ramps[:0]
+	for _, r := range run.ramps {
+		if !s.hasSensor(r.sensorID) {
+			continue
+		}
+		progress := 1.0
+		if r.duration > 0 {
+			progress = float64(run.elapsed-r.start) / float64(r.duration)
+		}
+		if progress >= 1 {
+			s.lastValues[r.sensorID] = r.to
+			continue
+		}
+		s.lastValues[r.sensorID] = r.from + (r.to-r.from)*progress
+		active = append(active, r)
+	}
+	run.ramps = active
+
+	if run.next >= len(run.scenario.Events) && len(run.ramps) == 0 {
+		run.state = ScenarioFinished
+	}
+}
+
+// fireEvent executa um evento de cenário sobre o estado do simulador
+func (s *Simulator) fireEvent(run *scenarioRun, event ScenarioEvent) {
+	// Sensores removidos em execução são ignorados
+	if !s.hasSensor(event.SensorID) {
+		return
+	}
+
+	switch event.Action {
+	case ActionSet:
+		s.lastValues[event.SensorID] = event.Value
+	case ActionRamp:
+		// Uma nova rampa substitui a rampa anterior do mesmo sensor
+		for i, r := range run.ramps {
+			if r.sensorID == event.SensorID {
+				run.ramps = append(run.ramps[:i], run.ramps[i+1:]...)
+				break
+			}
+		}
+		run.ramps = append(run.ramps, &ramp{
+			sensorID: event.SensorID,
+			from:     s.lastValues[event.SensorID],
+			to:       event.Value,
+			start:    time.Duration(event.At),
+			duration: time.Duration(event.Duration),
+		})
+	case ActionDrift:
+		s.driftFactors[event.SensorID] = event.Value
+	case ActionFault:
+		if _, err := s.addFault(event.SensorID, *event.Fault, s.clock.Now()); err != nil {
+			log.Printf("Erro ao injetar falha do cenário no sensor %s: %v", event.SensorID, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package simulator
+
+import (
+	"testing"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func TestLoadScenarioRejectsAbsoluteGenerators(t *testing.T) {
+	sensors := testSensors(2)
+	sensors[1].Generator = &models.GeneratorConfig{Name: "sine"}
+	sim := newTestSimulator(t, sensors, WithSeed(1))
+
+	for _, action := range []ScenarioAction{ActionSet, ActionRamp, ActionDrift} {
+		scenario := &Scenario{Events: []ScenarioEvent{{SensorID: sensors[1].ID, Action: action, Value: 1}}}
+		if err := sim.LoadScenario(scenario); err == nil {
+			t.Errorf("ação %s aceita em gerador senoidal", action)
+		}
+	}
+
+	// Geradores que partem do último valor aceitam as ações
+	scenario := &Scenario{Events: []ScenarioEvent{{SensorID: sensors[0].ID, Action: ActionSet, Value: 20}}}
+	if err := sim.LoadScenario(scenario); err != nil {
+		t.Fatalf("ação set rejeitada no ciclo diário: %v", err)
+	}
+}
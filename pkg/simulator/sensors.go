@@ -0,0 +1,220 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+var (
+	// ErrSensorNotFound indica que o sensor não existe na simulação
+	ErrSensorNotFound = errors.New("sensor não encontrado")
+	// ErrSensorExists indica que já existe um sensor com o mesmo ID
+	ErrSensorExists = errors.New("sensor já existe")
+)
+
+// WithSensorCallback registra a função chamada quando um sensor é adicionado (previous nil),
+// alterado ou removido (current nil) com a simulação em execução
+func WithSensorCallback(callback func(previous, current *models.SensorConfig)) Option {
+	return func(s *Simulator) {
+		s.sensorCallback = callback
+	}
+}
+
+// Sensor retorna a configuração efetiva do sensor
+func (s *Simulator) Sensor(id string) (models.SensorConfig, error) {
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
+
+	i, ok := s.sensorIndex[id]
+	if !ok {
+		return models.SensorConfig{}, fmt.Errorf("%w: %s", ErrSensorNotFound, id)
+	}
+	return s.configs[i], nil
+}
+
+// AddSensor adiciona um sensor à simulação em execução. Unidade e faixa omitidas recebem
+// os valores típicos do tipo, como na configuração inicial.
+func (s *Simulator) AddSensor(config models.SensorConfig) (models.SensorConfig, error) {
+	config = config.WithDefaults()
+	if config.ID == "" {
+		return config, fmt.Errorf("sensor sem id")
+	}
+
+	s.sensorsMu.Lock()
+	if s.hasSensor(config.ID) {
+		s.sensorsMu.Unlock()
+		return config, fmt.Errorf("%w: %s", ErrSensorExists, config.ID)
+	}
+	next := append(slices.Clone(s.configs), config)
+	err := s.replaceSensors(next, config.ID)
+	s.sensorsMu.Unlock()
+	if err != nil {
+		return config, err
+	}
+
+	s.notifySensor(nil, &config)
+	return config, nil
+}
+
+// UpdateSensor substitui a configuração de um sensor em execução. O sensor recomeça do
+// meio da faixa, com novo gerador, agendamento e cadeia de medição.
+func (s *Simulator) UpdateSensor(id string, config models.SensorConfig) (models.SensorConfig, error) {
+	if config.ID == "" {
+		config.ID = id
+	}
+	if config.ID != id {
+		return config, fmt.Errorf("o id do sensor não pode ser alterado (%s -> %s)", id, config.ID)
+	}
+	config = config.WithDefaults()
+
+	s.sensorsMu.Lock()
+	i, ok := s.sensorIndex[id]
+	if !ok {
+		s.sensorsMu.Unlock()
+		return config, fmt.Errorf("%w: %s", ErrSensorNotFound, id)
+	}
+	previous := s.configs[i]
+	next := slices.Clone(s.configs)
+	next[i] = config
+	err := s.replaceSensors(next, id)
+	s.sensorsMu.Unlock()
+	if err != nil {
+		return config, err
+	}
+
+	s.notifySensor(&previous, &config)
+	return config, nil
+}
+
+// RemoveSensor remove um sensor da simulação em execução, junto com as suas falhas.
+// Sensores usados por sensores derivados ou por efeitos de atuadores não podem ser removidos.
+func (s *Simulator) RemoveSensor(id string) error {
+	s.sensorsMu.Lock()
+	i, ok := s.sensorIndex[id]
+	if !ok {
+		s.sensorsMu.Unlock()
+		return fmt.Errorf("%w: %s", ErrSensorNotFound, id)
+	}
+	previous := s.configs[i]
+	err := s.checkActuatorEffects(id)
+	if err == nil {
+		err = s.replaceSensors(slices.Delete(slices.Clone(s.configs), i, i+1), "")
+	}
+	if err == nil {
+		s.removeSensorFaults(id)
+	}
+	s.sensorsMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.notifySensor(&previous, nil)
+	return nil
+}
+
+// checkActuatorEffects rejeita a remoção do sensor, ou a sua troca por um sensor derivado
+// ou de gerador absoluto, se ele recebe efeitos de atuadores
+func (s *Simulator) checkActuatorEffects(id string) error {
+	s.actuatorsMu.Lock()
+	defer s.actuatorsMu.Unlock()
+	for _, a := range s.actuators {
+		for _, effect := range a.config.Effects {
+			if effect.SensorID == id {
+				return fmt.Errorf("o sensor %s recebe efeitos do atuador %s", id, a.config.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceSensors troca a lista de sensores, preservando o estado dos sensores mantidos.
+// O sensor changed (adicionado ou alterado) recebe estado novo. Nada é alterado se a nova
+// lista for inválida. Deve ser chamado com sensorsMu travado.
+func (s *Simulator) replaceSensors(next []models.SensorConfig, changed string) error {
+	index := make(map[string]int, len(next))
+	for i, config := range next {
+		index[config.ID] = i
+	}
+
+	// Validar a nova lista antes de alterar o estado
+	derived, err := newDerivedSensors(next, index)
+	if err != nil {
+		return err
+	}
+	var generator Generator
+	var chain *measurementChain
+	if changed != "" {
+		config := next[index[changed]]
+		if err := validateSampling(config); err != nil {
+			return err
+		}
+		if err := validateBoundary(config); err != nil {
+			return err
+		}
+		if !config.Derived() {
+			if generator, err = s.newSensorGenerator(config); err != nil {
+				return err
+			}
+		}
+		// Efeitos de atuadores só atuam em geradores que partem do último valor
+		if generator == nil || !followsLastValue(generator) {
+			if err := s.checkActuatorEffects(changed); err != nil {
+				return err
+			}
+		}
+		if chain, err = newMeasurementChain(config, s.rng); err != nil {
+			return err
+		}
+	}
+
+	// Reposicionar o estado de cada sensor mantido
+	schedules := make([]sampleSchedule, len(next))
+	measurements := make([]*measurementChain, len(next))
+	latest := make([]models.SensorReading, len(next))
+	for i, config := range next {
+		if config.ID == changed {
+			schedules[i] = newSampleSchedule(config, s.lastTick, s.rng)
+			measurements[i] = chain
+			continue
+		}
+		old := s.sensorIndex[config.ID]
+		schedules[i] = s.schedules[old]
+		measurements[i] = s.measurements[old]
+		latest[i] = s.latest[old]
+	}
+
+	// Descartar o estado dos sensores removidos e iniciar o do sensor alterado
+	for id := range s.sensorIndex {
+		if _, ok := index[id]; !ok || id == changed {
+			delete(s.generators, id)
+			delete(s.lastValues, id)
+			delete(s.driftFactors, id)
+			delete(s.reported, id)
+		}
+	}
+	if changed != "" {
+		if generator != nil {
+			s.generators[changed] = generator
+		}
+		s.lastValues[changed], s.driftFactors[changed] = initialState(next[index[changed]], s.rng)
+	}
+
+	s.configs = next
+	s.sensorIndex = index
+	s.derived = derived
+	s.schedules = schedules
+	s.measurements = measurements
+	s.latest = latest
+	s.readings = s.latestReadings()
+	return nil
+}
+
+// notifySensor avisa o callback de sensores sobre uma alteração feita em execução
+func (s *Simulator) notifySensor(previous, current *models.SensorConfig) {
+	if s.sensorCallback != nil {
+		s.sensorCallback(previous, current)
+	}
+}
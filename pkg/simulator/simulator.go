@@ -1,179 +1,566 @@
 package simulator
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
 	"go-sensors-simulator/pkg/models"
 )
 
-// Inicializar o gerador de números aleatórios com uma semente baseada no tempo atual
-func init() {
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-}
-
 // Simulator representa o simulador de sensores
 type Simulator struct {
-	configs        []models.SensorConfig
-	readings       []models.SensorReading
-	lastValues     map[string]float64
-	changeCallback func([]models.SensorReading)
-	rng            *rand.Rand         // Gerador de números aleatórios dedicado
-	driftFactors   map[string]float64 // Fatores de drift para cada sensor
+	sensorsMu    sync.RWMutex // Protege os sensores e seu estado, alterados pela API durante a simulação
+	configs      []models.SensorConfig
+	readings     []models.SensorReading
+	lastValues   map[string]float64
+	rng          *rand.Rand             // Gerador de números aleatórios dedicado
+	driftFactors map[string]float64     // Fatores de drift para cada sensor
+	seed         int64                  // Semente usada pelo gerador de números aleatórios
+	clock        Clock                  // Relógio usado para timestamps e ciclos diários
+	generators   map[string]Generator   // Gerador de sinal de cada sensor físico
+	sensorIndex  map[string]int         // Posição de cada sensor na configuração
+	derived      []derivedSensor        // Sensores derivados em ordem de dependência
+	schedules    []sampleSchedule       // Agendamento de amostragem de cada sensor
+	reported     map[string]float64     // Último valor reportado de cada sensor, usado pelos derivados
+	latest       []models.SensorReading // Leitura mais recente de cada sensor
+	measurements []*measurementChain    // Cadeia de medição de cada sensor (nil = valor ideal)
+	workers      int                    // Número de shards processados em paralelo
+	shardRngs    []*rand.Rand           // Gerador de números aleatórios de cada shard
+	sources      []*pcgSource           // Fonte de cada gerador, cujo estado é salvo nos snapshots
+
+	growRoomConfigs []GrowRoomConfig     // Salas de cultivo configuradas
+	growRooms       map[string]*growRoom // Modelo físico de cada sala de cultivo
+
+	siteConfig *SiteConfig // Local da simulação (nil = sem modelo solar)
+	sun        *sun        // Modelo solar do local
+
+	weatherConfig *WeatherConfig // Regimes do tempo (nil = sem modelo do tempo)
+	weather       *weather       // Modelo de regimes do tempo
+
+	actuatorConfigs  []models.ActuatorConfig
+	actuatorCallback func([]models.ActuatorState)
+	actuatorsMu      sync.Mutex // Protege os atuadores, comandados pela API, MQTT e OPC-UA
+	actuators        map[string]*actuator
+	startTime        time.Time // Início da simulação (relógio do simulador)
+	lastTick         time.Time // Horário do último ciclo
+
+	faultsMu    sync.Mutex // Protege as falhas, alteradas pela API durante a simulação
+	faults      []*fault
+	nextFaultID int
+
+	scenarioMu sync.Mutex // Protege o cenário, controlado pela API durante a simulação
+	scenario   *scenarioRun
+
+	hub            hub              // Assinantes das leituras de cada ciclo
+	callbackConfig SubscriberConfig // Fila do callback informado em NewSimulator
+
+	sensorCallback func(previous, current *models.SensorConfig) // Sensores alterados em execução
+
+	lifecycleMu sync.Mutex         // Protege o ciclo de vida, controlado pela API durante a simulação
+	state       SimulationState    // Estado do ciclo de vida
+	interval    time.Duration      // Intervalo entre ciclos da execução atual
+	ticks       int64              // Ciclos executados
+	cancel      context.CancelFunc // Encerra a execução atual (nil = sem execução)
+	done        chan struct{}      // Fechado quando a execução atual termina
+	tickMu      sync.Mutex         // Serializa os ciclos do laço de execução e de Step
+}
+
+// Option configura parâmetros opcionais do simulador
+type Option func(*Simulator)
+
+// WithSeed define a semente do gerador de números aleatórios.
+// Uma semente zero faz o simulador escolher uma semente baseada no horário atual.
+func WithSeed(seed int64) Option {
+	return func(s *Simulator) {
+		s.seed = seed
+	}
+}
+
+// WithWorkers define em quantos shards paralelos os sensores são processados.
+// Com a mesma semente, o resultado é reproduzível para o mesmo número de workers.
+func WithWorkers(workers int) Option {
+	return func(s *Simulator) {
+		s.workers = workers
+	}
+}
+
+// WithClock define o relógio usado pelo simulador
+func WithClock(clock Clock) Option {
+	return func(s *Simulator) {
+		s.clock = clock
+	}
 }
 
 // NewSimulator cria um novo simulador de sensores
-func NewSimulator(configs []models.SensorConfig, callback func([]models.SensorReading)) *Simulator {
-	lastValues := make(map[string]float64)
-	driftFactors := make(map[string]float64)
+func NewSimulator(configs []models.SensorConfig, callback func([]models.SensorReading), opts ...Option) (*Simulator, error) {
+	// Completar unidade e faixa com os valores típicos de cada tipo
+	configs = append([]models.SensorConfig(nil), configs...)
+	for i := range configs {
+		configs[i] = configs[i].WithDefaults()
+	}
 
-	// Criar um gerador de números aleatórios dedicado
-	source := rand.NewSource(time.Now().UnixNano())
-	rng := rand.New(source)
+	s := &Simulator{
+		configs: configs,
+		clock:   realClock{},
+		state:   SimulationIdle,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
 
-	// Inicializa os valores com médias realistas e fatores de drift aleatórios
+	if s.workers < 1 {
+		s.workers = 1
+	}
+	if err := s.build(); err != nil {
+		return nil, err
+	}
+
+	// Criar os atuadores virtuais
+	if err := s.newActuators(); err != nil {
+		return nil, err
+	}
+
+	// Agendar as falhas definidas na configuração
 	for _, config := range configs {
-		baseValue := (config.MaxValue + config.MinValue) / 2
-		// Adicionar um pouco de aleatoriedade aos valores iniciais
-		baseValue += (rng.Float64()*2 - 1) * config.NoiseAmplitude * 2
-		lastValues[config.ID] = baseValue
+		for _, faultConfig := range config.Faults {
+			if _, err := s.addFault(config.ID, faultConfig, s.startTime); err != nil {
+				return nil, fmt.Errorf("falha inválida no sensor %s: %w", config.ID, err)
+			}
+		}
+	}
 
-		// Inicializar fatores de drift aleatórios para cada sensor
-		driftFactors[config.ID] = (rng.Float64()*2 - 1) * 0.02 // ±2% de drift por ciclo
+	// O callback informado é o primeiro assinante das leituras
+	if callback != nil {
+		if _, err := s.Subscribe("callback", s.callbackConfig, callback); err != nil {
+			return nil, err
+		}
 	}
 
-	return &Simulator{
-		configs:        configs,
-		readings:       []models.SensorReading{},
-		lastValues:     lastValues,
-		changeCallback: callback,
-		rng:            rng,
-		driftFactors:   driftFactors,
+	return s, nil
+}
+
+// build cria os modelos físicos e os geradores dos sensores e sorteia o estado inicial a
+// partir da semente. NewSimulator e ResetSimulation usam o mesmo caminho, de modo que a
+// mesma semente reproduz a mesma simulação.
+func (s *Simulator) build() error {
+	// Criar os modelos das salas de cultivo
+	growRooms, err := newGrowRooms(s.growRoomConfigs)
+	if err != nil {
+		return err
 	}
+	s.growRooms = growRooms
+
+	// Criar o modelo solar do local, com a cobertura de nuvens inicial
+	if s.sun, err = newSun(s.siteConfig); err != nil {
+		return err
+	}
+
+	// Criar o modelo de regimes do tempo
+	if s.weather, err = newWeather(s.weatherConfig); err != nil {
+		return err
+	}
+
+	// Criar o gerador de sinal de cada sensor físico
+	s.sensorIndex = make(map[string]int, len(s.configs))
+	s.generators = make(map[string]Generator, len(s.configs))
+	for i, config := range s.configs {
+		s.sensorIndex[config.ID] = i
+		if err := validateSampling(config); err != nil {
+			return err
+		}
+		if err := validateBoundary(config); err != nil {
+			return err
+		}
+		if config.Derived() {
+			continue
+		}
+		generator, err := s.newSensorGenerator(config)
+		if err != nil {
+			return err
+		}
+		s.generators[config.ID] = generator
+	}
+
+	// Compilar os sensores derivados, com as integrais diárias zeradas
+	if s.derived, err = newDerivedSensors(s.configs, s.sensorIndex); err != nil {
+		return err
+	}
+
+	return s.initState()
 }
 
-// Start inicia o simulador
-func (s *Simulator) Start(interval time.Duration) {
-	ticker := time.NewTicker(interval)
+// initState cria os geradores de números aleatórios a partir da semente, sorteia o valor
+// inicial e o fator de drift de cada sensor e cria as cadeias de medição
+func (s *Simulator) initState() error {
+	if s.seed == 0 {
+		s.seed = newSeed()
+	}
+	// Um gerador de números aleatórios dedicado para cada shard; o primeiro é o principal
+	s.shardRngs, s.sources = newShardRngs(s.seed, s.workers)
+	rng := s.shardRngs[0]
+
+	// Inicializa os valores com médias realistas e fatores de drift aleatórios
+	lastValues := make(map[string]float64)
+	driftFactors := make(map[string]float64)
+	for _, config := range s.configs {
+		lastValues[config.ID], driftFactors[config.ID] = initialState(config, rng)
+	}
+
+	s.lastValues = lastValues
+	s.driftFactors = driftFactors
+	s.rng = rng
+	s.startTime = s.clock.Now()
+	s.lastTick = s.startTime
+	s.readings = []models.SensorReading{}
+	s.resetSampling()
+	if s.weather != nil {
+		s.weather.reset(s.startTime, rng)
+	}
 
-	go func() {
-		for range ticker.C {
-			s.simulateReadings()
+	// Criar as cadeias de medição
+	s.measurements = make([]*measurementChain, len(s.configs))
+	for i, config := range s.configs {
+		var err error
+		if s.measurements[i], err = newMeasurementChain(config, rng); err != nil {
+			return err
 		}
-	}()
+	}
+	return nil
 }
 
-// simulateReadings gera novas leituras simuladas para todos os sensores
-func (s *Simulator) simulateReadings() {
-	readings := make([]models.SensorReading, 0, len(s.configs))
+// initialState sorteia o valor inicial, próximo ao meio da faixa, e o fator de drift do sensor
+func initialState(config models.SensorConfig, rng *rand.Rand) (value, drift float64) {
+	value = (config.MaxValue + config.MinValue) / 2
+	// Adicionar um pouco de aleatoriedade aos valores iniciais
+	value += (rng.Float64()*2 - 1) * config.NoiseAmplitude * 2
 
-	// Obter o timestamp atual para todas as leituras
-	now := time.Now()
-	hourOfDay := float64(now.Hour()) + float64(now.Minute())/60.0 // Hora do dia com fração para transições mais suaves
+	// Inicializar fatores de drift aleatórios para cada sensor
+	drift = (rng.Float64()*2 - 1) * 0.02 // ±2% de drift por ciclo
+	return value, drift
+}
 
-	for _, config := range s.configs {
-		// Calcular um novo valor com base no último valor e adicionando alguma variação
-		lastValue := s.lastValues[config.ID]
-
-		// Gerar uma variação aleatória dentro da amplitude de ruído configurada
-		noise := (s.rng.Float64()*2 - 1) * config.NoiseAmplitude
-
-		// Adicionar um drift lento ao longo do tempo
-		drift := s.driftFactors[config.ID] * lastValue
-
-		// Ocasionalmente, mudar a direção do drift
-		if s.rng.Float64() < 0.05 { // 5% de chance de mudar a direção
-			s.driftFactors[config.ID] = -s.driftFactors[config.ID] + (s.rng.Float64()*0.01 - 0.005) // Mudar e adicionar pequena variação
-		}
-
-		// Calcular um fator sazonal/cíclico para simular padrões reais
-		seasonalFactor := 0.0
-
-		// Diferentes padrões para diferentes tipos de sensores
-		switch config.Type {
-		case models.Temperature:
-			// Temperatura mais alta durante o dia, mais baixa à noite
-			dailyCycle := math.Sin((hourOfDay / 24) * 2 * math.Pi)
-			// Adicionar pequena variação de minuto em minuto
-			minuteCycle := math.Sin((float64(now.Minute())/60)*2*math.Pi) * 0.3
-			seasonalFactor = dailyCycle*2 + minuteCycle
-		case models.Humidity:
-			// Umidade inversamente relacionada à temperatura
-			dailyCycle := -math.Sin((hourOfDay / 24) * 2 * math.Pi)
-			// Adicionar pico de umidade aleatório ocasional (simulando chuva/irrigação)
-			if s.rng.Float64() < 0.01 { // 1% de chance
-				seasonalFactor = dailyCycle*3 + s.rng.Float64()*10
-			} else {
-				// Variação normal
-				minuteCycle := math.Sin((float64(now.Minute())/30)*2*math.Pi) * 0.8
-				seasonalFactor = dailyCycle*3 + minuteCycle
-			}
-		case models.Light:
-			// Luz alta durante o dia, baixa à noite com transições suaves
-			if hourOfDay >= 5 && hourOfDay <= 19 {
-				// Dia (forma de sino)
-				midday := 12.0
-				hourFactor := math.Pow((hourOfDay-midday)/7, 2)
-				baseFactor := 400 * math.Exp(-hourFactor)
-				// Adicionar variação para simular passagem de nuvens
-				cloudFactor := 1.0
-				if s.rng.Float64() < 0.1 { // 10% de chance de nuvens
-					cloudFactor = 0.5 + s.rng.Float64()*0.3
-				}
-				seasonalFactor = baseFactor * cloudFactor
-			} else {
-				// Noite - pequena luz para simular luz ambiente/artificial
-				seasonalFactor = s.rng.Float64() * 5
+// newShardRngs cria os geradores de cada shard e as suas fontes. O primeiro shard usa o
+// gerador principal; os demais usam sementes derivadas da semente da simulação.
+func newShardRngs(seed int64, workers int) ([]*rand.Rand, []*pcgSource) {
+	rngs := make([]*rand.Rand, workers)
+	sources := make([]*pcgSource, workers)
+	for k := range rngs {
+		sources[k] = newPCGSource(seed + int64(k)*0x9E3779B97F4A7C)
+		rngs[k] = rand.New(sources[k])
+	}
+	return rngs, sources
+}
+
+// newSeed gera uma semente não nula baseada no horário atual
+func newSeed() int64 {
+	seed := time.Now().UnixNano()
+	if seed == 0 {
+		seed = 1
+	}
+	return seed
+}
+
+// sensorResult é o resultado de um ciclo de simulação para um sensor
+type sensorResult struct {
+	value    float64                // Valor real do processo após a última amostra
+	drift    float64                // Fator de drift atualizado
+	schedule sampleSchedule         // Agendamento atualizado
+	readings []models.SensorReading // Leituras do ciclo, sem as descartadas por falha (dropout)
+	dropouts []models.SensorReading // Registros só com os rótulos das amostras descartadas
+	sampled  bool                   // O sensor foi amostrado no ciclo
+	dropped  bool                   // A última amostra do ciclo foi descartada
+}
+
+// simulateReadings gera novas leituras simuladas para todos os sensores.
+// Os sensores são divididos entre os shards, cada um com seu próprio gerador de
+// números aleatórios, e processados em paralelo.
+func (s *Simulator) simulateReadings(ctx context.Context) {
+	s.sensorsMu.Lock()
+
+	// Obter o timestamp atual do ciclo
+	now := s.clock.Now()
+	step := now.Sub(s.lastTick)
+	s.lastTick = now
+
+	// Executar eventos de cenário antes de gerar os novos valores
+	s.advanceScenario(now)
+	s.stepWeather(now, step)
+	s.stepSun(step)
+	roomLevels := s.applyActuators(step)
+	s.stepGrowRooms(now, step, roomLevels)
+	faults := s.faultsBySensor()
+
+	results := make([]sensorResult, len(s.configs))
+	simulateShard := func(rng *rand.Rand, lo, hi int) {
+		for i := lo; i < hi; i++ {
+			config := s.configs[i]
+			if config.Derived() {
+				continue
 			}
-		case models.Pressure:
-			// Pressão com variação menor e mais lenta + tendências aleatórias
-			dailyCycle := math.Sin((hourOfDay/48)*2*math.Pi) * 0.5
-			weekCycle := math.Sin((float64(now.Weekday())/7)*2*math.Pi) * 1.0
-			seasonalFactor = dailyCycle + weekCycle
+			results[i] = s.simulateSensor(config, s.schedules[i], s.measurements[i], now, rng, faults[config.ID])
 		}
+	}
 
-		// Calcular novo valor
-		newValue := lastValue + noise + seasonalFactor + drift
+	shards := len(s.shardRngs)
+	if shards == 1 {
+		simulateShard(s.shardRngs[0], 0, len(s.configs))
+	} else {
+		var wg sync.WaitGroup
+		for k := 0; k < shards; k++ {
+			lo, hi := k*len(s.configs)/shards, (k+1)*len(s.configs)/shards
+			wg.Add(1)
+			go func(rng *rand.Rand) {
+				defer wg.Done()
+				simulateShard(rng, lo, hi)
+			}(s.shardRngs[k])
+		}
+		wg.Wait()
+	}
 
-		// Garantir que o valor está dentro dos limites
-		if newValue < config.MinValue {
-			newValue = config.MinValue + s.rng.Float64()*config.NoiseAmplitude
+	// Armazenar os novos valores dos sensores físicos e calcular os derivados
+	for i, config := range s.configs {
+		if !config.Derived() {
+			s.storeResult(i, results[i])
+		}
+	}
+	s.simulateDerived(results, now, faults)
+
+	// Montar as leituras do ciclo em ordem cronológica, com os registros das amostras
+	// descartadas; amostras simultâneas mantêm a ordem da configuração
+	var readings []models.SensorReading
+	for _, result := range results {
+		readings = append(readings, result.readings...)
+		readings = append(readings, result.dropouts...)
+	}
+	sort.SliceStable(readings, func(a, b int) bool {
+		return readings[a].Timestamp.Before(readings[b].Timestamp)
+	})
+
+	// Atualizar leituras e notificar callbacks fora da trava, para que os destinos
+	// possam consultar o simulador
+	s.readings = s.latestReadings()
+	s.sensorsMu.Unlock()
+	s.Publish(ctx, readings)
+	s.publishActuators(now)
+}
+
+// storeResult grava o resultado do ciclo de um sensor no estado do simulador
+func (s *Simulator) storeResult(i int, result sensorResult) {
+	id := s.configs[i].ID
+	s.schedules[i] = result.schedule
+	if !result.sampled {
+		return
+	}
+
+	s.lastValues[id] = result.value
+	s.driftFactors[id] = result.drift
+	if n := len(result.readings); n > 0 {
+		s.latest[i] = result.readings[n-1]
+	}
+	if result.dropped {
+		delete(s.reported, id)
+		// A leitura atual do sensor mantém o último valor recebido, marcado com a falha
+		// de comunicação enquanto as amostras forem descartadas
+		if s.latest[i].SensorID != "" {
+			s.latest[i].Quality = models.QualityBadCommunicationError
+		}
+	} else {
+		s.reported[id] = s.latest[i].Value
+	}
+}
+
+// latestReadings retorna a leitura mais recente de cada sensor, na ordem da configuração
+func (s *Simulator) latestReadings() []models.SensorReading {
+	readings := make([]models.SensorReading, 0, len(s.latest))
+	for _, reading := range s.latest {
+		if reading.SensorID != "" {
+			readings = append(readings, reading)
+		}
+	}
+	return readings
+}
+
+// resetSampling reinicia o agendamento de amostragem e as últimas leituras dos sensores
+func (s *Simulator) resetSampling() {
+	s.schedules = make([]sampleSchedule, len(s.configs))
+	for i, config := range s.configs {
+		s.schedules[i] = newSampleSchedule(config, s.startTime, s.rng)
+	}
+	s.reported = make(map[string]float64, len(s.configs))
+	s.latest = make([]models.SensorReading, len(s.configs))
+}
+
+// simulateSensor calcula as amostras de um sensor no ciclo. Apenas lê o estado compartilhado;
+// a escrita dos resultados é feita por simulateReadings após todos os shards terminarem.
+func (s *Simulator) simulateSensor(config models.SensorConfig, schedule sampleSchedule, chain *measurementChain, now time.Time, rng *rand.Rand, faults []*fault) sensorResult {
+	result := sensorResult{
+		value: s.lastValues[config.ID],
+		drift: s.driftFactors[config.ID],
+	}
+
+	for _, at := range schedule.due(config, now, rng) {
+		// Calcular um novo valor com o gerador configurado para o sensor
+		ctx := &GeneratorContext{
+			Config:    config,
+			LastValue: result.value,
+			Drift:     &result.drift,
+			Now:       at,
+			Elapsed:   at.Sub(s.startTime),
+			Step:      at.Sub(schedule.last),
+			Rand:      rng,
 		}
-		if newValue > config.MaxValue {
-			newValue = config.MaxValue - s.rng.Float64()*config.NoiseAmplitude
+		newValue := s.generators[config.ID].Next(ctx)
+
+		// Garantir que o valor está dentro dos limites
+		newValue, label := applyBoundary(config, newValue, ctx.Step, rng)
+		if label != "" {
+			ctx.Label(label)
 		}
+		result.value = newValue
+		result.sampled = true
 
-		// Armazenar o novo valor
-		s.lastValues[config.ID] = newValue
+		// Aplicar a cadeia de medição e as falhas ao valor reportado, sem alterar o
+		// valor real do processo
+		measured := newValue
+		if chain != nil {
+			measured = chain.apply(newValue, at.Sub(schedule.last))
+		}
+		schedule.last = at
+		reported, active, dropped := applyFaults(rng, config, measured, at, faults)
+		result.dropped = dropped
+		if dropped {
+			result.dropouts = append(result.dropouts, droppedReading(config, at, ctx.labels, active))
+			continue
+		}
 
 		// Criar a leitura do sensor
-		reading := models.NewSensorReading(config, newValue)
-		readings = append(readings, reading)
+		reading := models.NewSensorReadingAt(config, reported, at)
+		reading.Faults = active
+		reading.Labels = readingLabels(config, reported, ctx.labels, active)
+		reading.Quality = readingQuality(config, reported, ctx.labels, active)
+		result.readings = append(result.readings, reading)
 	}
 
-	// Atualizar leituras e notificar callback
-	s.readings = readings
-	if s.changeCallback != nil {
-		s.changeCallback(readings)
+	result.schedule = schedule
+	return result
+}
+
+// readingLabels reúne os eventos marcados pelo gerador, as falhas ativas e o alarme de
+// faixa de operação de uma leitura
+func readingLabels(config models.SensorConfig, value float64, labels []models.AnomalyLabel, faults []models.FaultType) []models.AnomalyLabel {
+	for _, f := range faults {
+		labels = append(labels, models.FaultLabel(f))
+	}
+	if alarm := operatingLabel(config, value); alarm != "" {
+		labels = append(labels, alarm)
 	}
+	return labels
+}
+
+// droppedReading cria o registro de uma amostra descartada, sem valor, para que os rótulos
+// do instante não se percam
+func droppedReading(config models.SensorConfig, at time.Time, labels []models.AnomalyLabel, faults []models.FaultType) models.SensorReading {
+	reading := models.NewSensorReadingAt(config, math.NaN(), at)
+	reading.Faults = faults
+	reading.Labels = readingLabels(config, reading.Value, labels, faults)
+	reading.Quality = models.QualityBadCommunicationError
+	reading.Dropped = true
+	return reading
+}
+
+// readingQuality define a qualidade da leitura a partir do limite aplicado ao valor
+// gerado, das falhas ativas e do valor reportado
+func readingQuality(config models.SensorConfig, value float64, labels []models.AnomalyLabel, faults []models.FaultType) models.Quality {
+	quality := models.QualityGood
+	for _, label := range labels {
+		if label == models.LabelClampMin || label == models.LabelClampMax {
+			quality = quality.Worst(models.QualityUncertainEngineeringUnitsExceeded)
+		}
+	}
+	for _, f := range faults {
+		switch f {
+		case models.FaultStuck, models.FaultFlatline:
+			quality = quality.Worst(models.QualityUncertainLastUsableValue)
+		case models.FaultSpike, models.FaultDrift, models.FaultOffset:
+			quality = quality.Worst(models.QualityUncertainSensorNotAccurate)
+		case models.FaultSaturation:
+			quality = quality.Worst(models.QualityUncertainEngineeringUnitsExceeded)
+		}
+	}
+
+	switch {
+	case math.IsNaN(value) || math.IsInf(value, 0):
+		quality = models.QualityBadSensorFailure
+	case config.MaxValue > config.MinValue && (value < config.MinValue || value > config.MaxValue):
+		// Sensores sem faixa física (comum nos derivados) não são verificados
+		quality = models.QualityBadOutOfRange
+	}
+	return quality
+}
+
+// Configs retorna as configurações dos sensores simulados
+func (s *Simulator) Configs() []models.SensorConfig {
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
+	return append([]models.SensorConfig(nil), s.configs...)
 }
 
 // GetReadings retorna as leituras mais recentes
 func (s *Simulator) GetReadings() []models.SensorReading {
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
 	return s.readings
 }
 
-// ResetSimulation reinicia a simulação com novos valores aleatórios
-func (s *Simulator) ResetSimulation() {
-	// Criar um novo gerador com nova semente
-	s.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+// Now retorna o horário atual da simulação
+func (s *Simulator) Now() time.Time {
+	return s.clock.Now()
+}
 
-	// Reinicializar valores e fatores de drift
-	for _, config := range s.configs {
-		baseValue := (config.MaxValue + config.MinValue) / 2
-		baseValue += (s.rng.Float64()*2 - 1) * config.NoiseAmplitude * 3
-		s.lastValues[config.ID] = baseValue
-		s.driftFactors[config.ID] = (s.rng.Float64()*2 - 1) * 0.02
+// TimeSpeed retorna o multiplicador de velocidade do relógio da simulação
+// (1 quando o relógio não tem velocidade ajustável)
+func (s *Simulator) TimeSpeed() float64 {
+	if controller, ok := s.clock.(SpeedController); ok {
+		return controller.Speed()
+	}
+	return 1
+}
+
+// SetTimeSpeed altera o multiplicador de velocidade do relógio da simulação
+func (s *Simulator) SetTimeSpeed(speed float64) error {
+	controller, ok := s.clock.(SpeedController)
+	if !ok {
+		return fmt.Errorf("o relógio da simulação não permite ajuste de velocidade")
+	}
+	return controller.SetSpeed(speed)
+}
+
+// Seed retorna a semente em uso pelo gerador de números aleatórios
+func (s *Simulator) Seed() int64 {
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
+	return s.seed
+}
+
+// ResetSimulation reinicia a simulação, com os modelos e os geradores no estado inicial,
+// como um simulador novo criado com a mesma semente. Se seed for zero, uma nova semente
+// baseada no horário atual é escolhida. Retorna a semente efetivamente usada.
+func (s *Simulator) ResetSimulation(seed int64) (int64, error) {
+	s.sensorsMu.Lock()
+	defer s.sensorsMu.Unlock()
+
+	// Usar a semente informada ou uma nova
+	if seed == 0 {
+		seed = newSeed()
+	}
+	s.seed = seed
+	if err := s.build(); err != nil {
+		return 0, fmt.Errorf("falha ao reiniciar a simulação: %w", err)
 	}
+	return seed, nil
 }
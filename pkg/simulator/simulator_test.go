@@ -0,0 +1,276 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// testStart é o instante inicial dos relógios manuais dos testes
+var testStart = time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+
+// testSensors cria n sensores alternando entre os tipos básicos
+func testSensors(n int) []models.SensorConfig {
+	types := []models.SensorConfig{
+		{Type: models.Temperature, MinValue: 15, MaxValue: 35, NoiseAmplitude: 0.5, Unit: "°C"},
+		{Type: models.Humidity, MinValue: 30, MaxValue: 90, NoiseAmplitude: 2, Unit: "%"},
+		{Type: models.Light, MinValue: 0, MaxValue: 1000, NoiseAmplitude: 50, Unit: "lux"},
+		{Type: models.Pressure, MinValue: 990, MaxValue: 1030, NoiseAmplitude: 1, Unit: "hPa"},
+	}
+	sensors := make([]models.SensorConfig, n)
+	for i := range sensors {
+		sensors[i] = types[i%len(types)]
+		sensors[i].ID = fmt.Sprintf("s%05d", i)
+	}
+	return sensors
+}
+
+// newTestSimulator cria um simulador com relógio manual
+func newTestSimulator(t testing.TB, sensors []models.SensorConfig, opts ...Option) *Simulator {
+	t.Helper()
+	opts = append([]Option{WithClock(NewManualClock(testStart))}, opts...)
+	sim, err := NewSimulator(sensors, nil, opts...)
+	if err != nil {
+		t.Fatalf("NewSimulator: %v", err)
+	}
+	return sim
+}
+
+// collectSteps executa steps ciclos, avançando o relógio manual um segundo antes de
+// cada um, e retorna as leituras entregues em cada ciclo
+func collectSteps(t testing.TB, sim *Simulator, steps int) [][]models.SensorReading {
+	t.Helper()
+	clock, ok := sim.clock.(*ManualClock)
+	if !ok {
+		t.Fatal("o simulador de teste deve usar um relógio manual")
+	}
+
+	var batches [][]models.SensorReading
+	sub, err := sim.Subscribe("test", SubscriberConfig{}, func(readings []models.SensorReading) {
+		batches = append(batches, append([]models.SensorReading(nil), readings...))
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	for i := 0; i < steps; i++ {
+		clock.Advance(time.Second)
+		sim.simulateReadings(context.Background())
+	}
+	return batches
+}
+
+func TestSeedDeterminism(t *testing.T) {
+	sensors := testSensors(8)
+	first := collectSteps(t, newTestSimulator(t, sensors, WithSeed(42)), 50)
+	second := collectSteps(t, newTestSimulator(t, sensors, WithSeed(42)), 50)
+
+	if len(first) != 50 {
+		t.Fatalf("esperados 50 lotes, recebidos %d", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("simuladores com a mesma semente geraram leituras diferentes")
+	}
+
+	other := collectSteps(t, newTestSimulator(t, sensors, WithSeed(43)), 50)
+	if reflect.DeepEqual(first, other) {
+		t.Fatal("simuladores com sementes diferentes geraram as mesmas leituras")
+	}
+}
+
+func TestResetSimulationMatchesNewSimulator(t *testing.T) {
+	// Os modelos da sala de cultivo, do sol e do tempo também voltam ao estado inicial
+	sensors := append(testSensors(8), models.SensorConfig{
+		ID: "room-temp", Type: models.Temperature, MinValue: 0, MaxValue: 50,
+		Generator: &models.GeneratorConfig{Name: "grow_room", Room: "sala1"},
+	}, models.SensorConfig{
+		ID: "outside-temp", Type: models.Temperature, MinValue: 0, MaxValue: 40,
+		Generator: &models.GeneratorConfig{Name: "solar_temperature"},
+	}, models.SensorConfig{
+		ID: "outside-hum", Type: models.Humidity, MinValue: 20, MaxValue: 100,
+		Generator: &models.GeneratorConfig{Name: "weather"},
+	})
+	rooms := WithGrowRooms([]GrowRoomConfig{{ID: "sala1", Stage: "flower"}})
+	site := WithSite(&SiteConfig{Latitude: -23.5, Longitude: -46.6})
+	weather := WithWeather(&WeatherConfig{})
+	sim := newTestSimulator(t, sensors, WithSeed(1), rooms, site, weather)
+	collectSteps(t, sim, 30)
+
+	// Um simulador novo com a mesma semente, a partir do mesmo horário
+	if seed, err := sim.ResetSimulation(77); err != nil || seed != 77 {
+		t.Fatalf("ResetSimulation = %d, %v; esperada a semente 77", seed, err)
+	}
+	now := sim.clock.Now()
+	fresh := collectSteps(t, newTestSimulator(t, sensors, WithSeed(77), rooms, site, weather, WithClock(NewManualClock(now))), 20)
+	afterReset := collectSteps(t, sim, 20)
+	if !reflect.DeepEqual(afterReset, fresh) {
+		t.Fatal("a simulação reiniciada difere de um simulador novo com a mesma semente")
+	}
+
+	// Reiniciar de novo com a mesma semente repete a simulação
+	sim.clock.(*ManualClock).Set(now)
+	if _, err := sim.ResetSimulation(77); err != nil {
+		t.Fatalf("ResetSimulation: %v", err)
+	}
+	if again := collectSteps(t, sim, 20); !reflect.DeepEqual(again, afterReset) {
+		t.Fatal("duas reinicializações com a mesma semente geraram leituras diferentes")
+	}
+}
+
+func TestShardedDeterminism(t *testing.T) {
+	sensors := testSensors(64)
+	var shapes [][]string
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			first := collectSteps(t, newTestSimulator(t, sensors, WithSeed(7), WithWorkers(workers)), 20)
+			second := collectSteps(t, newTestSimulator(t, sensors, WithSeed(7), WithWorkers(workers)), 20)
+			if !reflect.DeepEqual(first, second) {
+				t.Fatalf("execuções com %d workers e a mesma semente divergiram", workers)
+			}
+
+			var shape []string
+			for _, batch := range first {
+				for _, reading := range batch {
+					shape = append(shape, reading.SensorID+"@"+reading.Timestamp.Format(time.RFC3339Nano))
+				}
+			}
+			shapes = append(shapes, shape)
+		})
+	}
+
+	// Os shards usam sequências aleatórias próprias, mas geram as mesmas amostras,
+	// dos mesmos sensores, nos mesmos instantes
+	if len(shapes) == 2 && !reflect.DeepEqual(shapes[0], shapes[1]) {
+		t.Fatal("1 e 4 workers geraram conjuntos de amostras diferentes")
+	}
+}
+
+func BenchmarkSimulateReadings(b *testing.B) {
+	for _, count := range []int{1000, 10000, 100000} {
+		for _, workers := range []int{1, 4, 8} {
+			b.Run(fmt.Sprintf("sensors=%d/workers=%d", count, workers), func(b *testing.B) {
+				sim := newTestSimulator(b, testSensors(count), WithSeed(1), WithWorkers(workers))
+				clock := sim.clock.(*ManualClock)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					clock.Advance(time.Second)
+					sim.simulateReadings(context.Background())
+				}
+				b.ReportMetric(float64(count)*float64(b.N)/b.Elapsed().Seconds(), "leituras/s")
+			})
+		}
+	}
+}
+
+func TestTemplatedDerivedSensors(t *testing.T) {
+	template := models.SensorTemplate{
+		Prefix: "room",
+		Count:  3,
+		Sensors: []models.SensorConfig{
+			{ID: "temp", Type: models.Temperature},
+			{ID: "hum", Type: models.Humidity},
+			{ID: "dew", Type: models.DewPoint, Expression: "dew_point(temp, hum)"},
+		},
+	}
+	sim := newTestSimulator(t, template.Expand(), WithSeed(3))
+	batches := collectSteps(t, sim, 1)
+
+	values := make(map[string]float64)
+	for _, reading := range batches[0] {
+		values[reading.SensorID] = reading.Value
+	}
+	for _, group := range []string{"room001", "room002", "room003"} {
+		want := dewPoint(values[group+"-temp"], values[group+"-hum"])
+		if got, ok := values[group+"-dew"]; !ok || got != want {
+			t.Errorf("%s-dew = %v, esperado %v", group, got, want)
+		}
+	}
+}
+
+func TestDropoutKeepsLabels(t *testing.T) {
+	sensors := testSensors(2)
+	sensors[0].Faults = []models.FaultConfig{{Type: models.FaultDropout}}
+	sim := newTestSimulator(t, sensors, WithSeed(7))
+
+	var labeled [][]models.SensorReading
+	sub, err := sim.Subscribe("labels", SubscriberConfig{IncludeDropped: true}, func(readings []models.SensorReading) {
+		labeled = append(labeled, append([]models.SensorReading(nil), readings...))
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+	batches := collectSteps(t, sim, 3)
+	if len(labeled) != len(batches) {
+		t.Fatalf("assinante de rótulos recebeu %d ciclos, esperados %d", len(labeled), len(batches))
+	}
+
+	for i, batch := range labeled {
+		var dropped *models.SensorReading
+		for j, reading := range batch {
+			if reading.SensorID == sensors[0].ID {
+				dropped = &batch[j]
+			}
+		}
+		if dropped == nil || !dropped.Dropped {
+			t.Fatalf("ciclo %d: amostra descartada sem registro de rótulos", i)
+		}
+		if !reflect.DeepEqual(dropped.Labels, []models.AnomalyLabel{models.FaultLabel(models.FaultDropout)}) {
+			t.Fatalf("ciclo %d: rótulos = %v", i, dropped.Labels)
+		}
+
+		// Os demais assinantes e as últimas leituras não recebem o registro descartado
+		for _, reading := range batches[i] {
+			if reading.Dropped {
+				t.Fatalf("ciclo %d: registro descartado entregue a assinante sem IncludeDropped", i)
+			}
+		}
+	}
+	for _, reading := range sim.GetReadings() {
+		if reading.SensorID == sensors[0].ID {
+			t.Fatal("amostra descartada aparece nas últimas leituras")
+		}
+	}
+}
+
+func TestDropoutCommunicationQuality(t *testing.T) {
+	sensors := []models.SensorConfig{
+		{ID: "temp", Type: models.Temperature, Faults: []models.FaultConfig{{Type: models.FaultDropout, Start: models.Duration(2 * time.Second)}}},
+		{ID: "hum", Type: models.Humidity},
+		{ID: "from_temp", Type: models.Temperature, Expression: "temp + 1"},
+	}
+	sim := newTestSimulator(t, sensors, WithSeed(9))
+	batches := collectSteps(t, sim, 4)
+
+	// Últimas leituras entregues antes do dropout
+	delivered := make(map[string]models.SensorReading)
+	for _, batch := range batches {
+		for _, reading := range batch {
+			delivered[reading.SensorID] = reading
+		}
+	}
+
+	current := sim.GetReadings()
+	if len(current) != len(sensors) {
+		t.Fatalf("esperadas %d leituras atuais, encontradas %d", len(sensors), len(current))
+	}
+	for _, reading := range current {
+		want := models.QualityGood
+		if reading.SensorID != "hum" {
+			want = models.QualityBadCommunicationError
+		}
+		if reading.Quality != want {
+			t.Errorf("%s: qualidade %v, esperada %v", reading.SensorID, reading.Quality, want)
+		}
+		if last := delivered[reading.SensorID]; reading.Value != last.Value || !reading.Timestamp.Equal(last.Timestamp) {
+			t.Errorf("%s: leitura atual %v@%v, esperado o último valor recebido %v@%v",
+				reading.SensorID, reading.Value, reading.Timestamp, last.Value, last.Timestamp)
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// SnapshotVersion é a versão do formato de snapshot gerado por Snapshot
+const SnapshotVersion = 1
+
+// Snapshot é o estado completo de uma simulação, salvo em JSON para ser retomado depois
+// ou em outra máquina com a mesma configuração de sensores
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"` // Horário real em que o snapshot foi gerado
+	Seed      int64     `json:"seed"`
+	Now       time.Time `json:"now"` // Horário simulado
+	StartTime time.Time `json:"start_time"`
+	LastTick  time.Time `json:"last_tick"`
+
+	Rand        []RandState        `json:"rand"` // Gerador principal e de cada shard
+	Sensors     []SensorState      `json:"sensors"`
+	Faults      []FaultState       `json:"faults,omitempty"`
+	NextFaultID int                `json:"next_fault_id"`
+	Scenario    *ScenarioRunState  `json:"scenario,omitempty"`
+	Actuators   map[string]float64 `json:"actuators,omitempty"` // Nível de cada atuador
+	GrowRooms   []GrowRoomState    `json:"grow_rooms,omitempty"`
+	CloudCover  *float64           `json:"cloud_cover,omitempty"` // Nebulosidade do modelo solar
+	Weather     *WeatherState      `json:"weather,omitempty"`
+}
+
+// RandState é o estado interno de um gerador de números aleatórios (PCG serializado)
+type RandState struct {
+	PCG []byte `json:"pcg"`
+}
+
+// SensorState é o estado de um sensor em um snapshot
+type SensorState struct {
+	ID          string                `json:"id"`
+	Value       jsonFloat             `json:"value"` // Valor real do processo
+	Drift       float64               `json:"drift"`
+	Sample      SampleState           `json:"sample"`
+	Latest      *models.SensorReading `json:"latest,omitempty"`
+	Reported    bool                  `json:"reported"` // A última amostra não foi descartada
+	Measurement *MeasurementState     `json:"measurement,omitempty"`
+	Generator   *GeneratorState       `json:"generator,omitempty"`
+	DailyTotals []DailyTotalState     `json:"daily_totals,omitempty"` // Integrais diárias dos derivados
+}
+
+// SampleState é o agendamento de amostragem de um sensor
+type SampleState struct {
+	Nominal time.Time `json:"nominal"`
+	Next    time.Time `json:"next"`
+	Last    time.Time `json:"last"`
+}
+
+// DailyTotalState é o acumulado de uma integral diária (dli) de um sensor derivado
+type DailyTotalState struct {
+	Day   time.Time `json:"day"`
+	Total float64   `json:"total"`
+}
+
+// FaultState é uma falha agendada em um snapshot
+type FaultState struct {
+	ID       int                `json:"id"`
+	SensorID string             `json:"sensor_id"`
+	Config   models.FaultConfig `json:"config"`
+	StartAt  time.Time          `json:"start_at"`
+	EndAt    time.Time          `json:"end_at"`
+	Held     *jsonFloat         `json:"held,omitempty"` // Valor capturado por falhas stuck/flatline
+}
+
+// ScenarioRunState é a posição do cenário carregado em um snapshot
+type ScenarioRunState struct {
+	Scenario Scenario        `json:"scenario"`
+	State    ScenarioState   `json:"state"`
+	Elapsed  models.Duration `json:"elapsed"`
+	LastTick time.Time       `json:"last_tick"`
+	Next     int             `json:"next"` // Índice do próximo evento
+	Ramps    []RampState     `json:"ramps,omitempty"`
+}
+
+// RampState é uma rampa de cenário em andamento
+type RampState struct {
+	SensorID string          `json:"sensor_id"`
+	From     float64         `json:"from"`
+	To       float64         `json:"to"`
+	Start    models.Duration `json:"start"`
+	Duration models.Duration `json:"duration"`
+}
+
+// jsonFloat é um float64 que aceita NaN e ±Inf em JSON, escritos como texto
+type jsonFloat float64
+
+// MarshalJSON serializa o valor; NaN e ±Inf são escritos como "NaN", "+Inf" e "-Inf"
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return json.Marshal(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON aceita números e os textos gerados por MarshalJSON
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("valor inválido %q: %w", text, err)
+		}
+		*f = jsonFloat(v)
+		return nil
+	}
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = jsonFloat(v)
+	return nil
+}
+
+// pcgStream é a segunda semente dos PCGs do simulador, fixa para que a semente da simulação
+// determine toda a sequência
+const pcgStream = 0xda3e39cb94b95bdb
+
+// pcgSource é a fonte dos geradores de números aleatórios do simulador: um PCG de
+// math/rand/v2, cujo estado interno é salvo diretamente nos snapshots
+type pcgSource struct {
+	pcg *randv2.PCG
+}
+
+// newPCGSource cria uma fonte com a semente informada
+func newPCGSource(seed int64) *pcgSource {
+	return &pcgSource{pcg: randv2.NewPCG(uint64(seed), pcgStream)}
+}
+
+// Int63, Uint64 e Seed implementam rand.Source64
+func (p *pcgSource) Int63() int64 {
+	return int64(p.pcg.Uint64() >> 1)
+}
+
+func (p *pcgSource) Uint64() uint64 {
+	return p.pcg.Uint64()
+}
+
+func (p *pcgSource) Seed(seed int64) {
+	p.pcg.Seed(uint64(seed), pcgStream)
+}
+
+// state retorna o estado interno da fonte
+func (p *pcgSource) state() RandState {
+	data, _ := p.pcg.MarshalBinary() // O PCG não falha ao serializar
+	return RandState{PCG: data}
+}
+
+// source recria a fonte a partir do estado salvo
+func (state RandState) source() (*pcgSource, error) {
+	pcg := &randv2.PCG{}
+	if err := pcg.UnmarshalBinary(state.PCG); err != nil {
+		return nil, fmt.Errorf("estado do gerador de números aleatórios inválido: %w", err)
+	}
+	return &pcgSource{pcg: pcg}, nil
+}
+
+// ParseSnapshot interpreta um snapshot em JSON, verificando a versão do formato
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar snapshot: %w", err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("versão de snapshot não suportada: %d (esperada %d)", snapshot.Version, SnapshotVersion)
+	}
+	return &snapshot, nil
+}
+
+// LoadSnapshotFile lê um snapshot de um arquivo JSON
+func LoadSnapshotFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de snapshot: %w", err)
+	}
+	return ParseSnapshot(data)
+}
+
+// Snapshot salva o estado completo da simulação. O ciclo em andamento termina antes da
+// captura, de modo que o estado é consistente mesmo com a simulação em execução.
+func (s *Simulator) Snapshot() *Snapshot {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+	s.sensorsMu.RLock()
+	defer s.sensorsMu.RUnlock()
+
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Now(),
+		Seed:      s.seed,
+		Now:       s.clock.Now(),
+		StartTime: s.startTime,
+		LastTick:  s.lastTick,
+	}
+	for _, source := range s.sources {
+		snapshot.Rand = append(snapshot.Rand, source.state())
+	}
+
+	// Estado de cada sensor
+	snapshot.Sensors = make([]SensorState, len(s.configs))
+	for i, config := range s.configs {
+		sch := s.schedules[i]
+		state := SensorState{
+			ID:     config.ID,
+			Value:  jsonFloat(s.lastValues[config.ID]),
+			Drift:  s.driftFactors[config.ID],
+			Sample: SampleState{Nominal: sch.nominal, Next: sch.next, Last: sch.last},
+		}
+		if latest := s.latest[i]; latest.SensorID != "" {
+			state.Latest = &latest
+		}
+		_, state.Reported = s.reported[config.ID]
+		if chain := s.measurements[i]; chain != nil {
+			measurement := chain.state()
+			state.Measurement = &measurement
+		}
+		if generator, ok := s.generators[config.ID].(statefulGenerator); ok {
+			generatorState := generator.saveState()
+			state.Generator = &generatorState
+		}
+		snapshot.Sensors[i] = state
+	}
+	for _, d := range s.derived {
+		for _, node := range d.expr.accumulators() {
+			snapshot.Sensors[d.index].DailyTotals = append(snapshot.Sensors[d.index].DailyTotals,
+				DailyTotalState{Day: node.day, Total: node.total})
+		}
+	}
+
+	// Falhas agendadas
+	s.faultsMu.Lock()
+	for _, f := range s.faults {
+		state := FaultState{ID: f.id, SensorID: f.sensorID, Config: f.config, StartAt: f.startAt, EndAt: f.endAt}
+		if f.heldSet {
+			held := jsonFloat(f.held)
+			state.Held = &held
+		}
+		snapshot.Faults = append(snapshot.Faults, state)
+	}
+	snapshot.NextFaultID = s.nextFaultID
+	s.faultsMu.Unlock()
+
+	// Posição do cenário
+	s.scenarioMu.Lock()
+	if run := s.scenario; run != nil {
+		state := &ScenarioRunState{
+			Scenario: *run.scenario,
+			State:    run.state,
+			Elapsed:  models.Duration(run.elapsed),
+			LastTick: run.lastTick,
+			Next:     run.next,
+		}
+		for _, r := range run.ramps {
+			state.Ramps = append(state.Ramps, RampState{
+				SensorID: r.sensorID,
+				From:     r.from,
+				To:       r.to,
+				Start:    models.Duration(r.start),
+				Duration: models.Duration(r.duration),
+			})
+		}
+		snapshot.Scenario = state
+	}
+	s.scenarioMu.Unlock()
+
+	// Atuadores e modelos físicos
+	if len(s.actuators) > 0 {
+		s.actuatorsMu.Lock()
+		snapshot.Actuators = make(map[string]float64, len(s.actuators))
+		for id, a := range s.actuators {
+			snapshot.Actuators[id] = a.level
+		}
+		s.actuatorsMu.Unlock()
+	}
+	for _, room := range s.growRooms {
+		snapshot.GrowRooms = append(snapshot.GrowRooms, room.state)
+	}
+	sort.Slice(snapshot.GrowRooms, func(i, j int) bool { return snapshot.GrowRooms[i].ID < snapshot.GrowRooms[j].ID })
+	if s.sun != nil {
+		cover := s.sun.cloudCover()
+		snapshot.CloudCover = &cover
+	}
+	if s.weather != nil {
+		weather := s.weather.state()
+		snapshot.Weather = &weather
+	}
+	return snapshot
+}
+
+// Restore retoma a simulação a partir de um snapshot. Todos os sensores do snapshot devem
+// existir na simulação; sensores ausentes do snapshot mantêm o estado atual. Relógios
+// manuais e virtuais voltam ao horário salvo; com o relógio do sistema, o intervalo desde
+// o snapshot é descartado sem gerar leituras.
+func (s *Simulator) Restore(snapshot *Snapshot) error {
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("versão de snapshot não suportada: %d (esperada %d)", snapshot.Version, SnapshotVersion)
+	}
+	if len(snapshot.Rand) == 0 {
+		return fmt.Errorf("snapshot sem estado do gerador de números aleatórios")
+	}
+
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+	s.sensorsMu.Lock()
+	defer s.sensorsMu.Unlock()
+
+	// Validar o snapshot antes de alterar o estado
+	states := make(map[string]*SensorState, len(snapshot.Sensors))
+	for i, state := range snapshot.Sensors {
+		if !s.hasSensor(state.ID) {
+			return fmt.Errorf("o sensor %s do snapshot não existe na simulação", state.ID)
+		}
+		states[state.ID] = &snapshot.Sensors[i]
+	}
+	for _, f := range snapshot.Faults {
+		if !s.hasSensor(f.SensorID) {
+			return fmt.Errorf("a falha %d do snapshot usa o sensor desconhecido %s", f.ID, f.SensorID)
+		}
+	}
+	if snapshot.Scenario != nil {
+		if err := s.validateScenario(&snapshot.Scenario.Scenario); err != nil {
+			return fmt.Errorf("cenário do snapshot inválido: %w", err)
+		}
+	}
+	for _, room := range snapshot.GrowRooms {
+		if _, ok := s.growRooms[room.ID]; !ok {
+			return fmt.Errorf("a sala de cultivo %s do snapshot não existe na simulação", room.ID)
+		}
+	}
+	if snapshot.Weather != nil && s.weather != nil {
+		if err := s.weather.validate(*snapshot.Weather); err != nil {
+			return fmt.Errorf("estado do tempo do snapshot inválido: %w", err)
+		}
+	}
+	sources := make([]*pcgSource, len(snapshot.Rand))
+	for k, state := range snapshot.Rand {
+		var err error
+		if sources[k], err = state.source(); err != nil {
+			return err
+		}
+	}
+
+	// Relógio e geradores de números aleatórios
+	switch clock := s.clock.(type) {
+	case *ManualClock:
+		clock.Set(snapshot.Now)
+	case *VirtualClock:
+		clock.Set(snapshot.Now)
+	}
+	s.seed = snapshot.Seed
+	s.workers = len(snapshot.Rand)
+	s.sources = sources
+	s.shardRngs = make([]*rand.Rand, len(sources))
+	for k, source := range sources {
+		s.shardRngs[k] = rand.New(source)
+	}
+	s.rng = s.shardRngs[0]
+	s.startTime = snapshot.StartTime
+	s.lastTick = snapshot.LastTick
+
+	// Estado de cada sensor
+	for _, state := range snapshot.Sensors {
+		i := s.sensorIndex[state.ID]
+		s.lastValues[state.ID] = float64(state.Value)
+		s.driftFactors[state.ID] = state.Drift
+		s.schedules[i] = sampleSchedule{nominal: state.Sample.Nominal, next: state.Sample.Next, last: state.Sample.Last}
+		s.latest[i] = models.SensorReading{}
+		if state.Latest != nil {
+			s.latest[i] = *state.Latest
+		}
+		delete(s.reported, state.ID)
+		if state.Reported && state.Latest != nil {
+			s.reported[state.ID] = state.Latest.Value
+		}
+		if chain := s.measurements[i]; chain != nil && state.Measurement != nil {
+			chain.restore(*state.Measurement)
+		}
+		if generator, ok := s.generators[state.ID].(statefulGenerator); ok && state.Generator != nil {
+			generator.loadState(*state.Generator)
+		}
+	}
+	for _, d := range s.derived {
+		state := states[s.configs[d.index].ID]
+		nodes := d.expr.accumulators()
+		if state == nil || len(state.DailyTotals) != len(nodes) {
+			continue
+		}
+		for k, node := range nodes {
+			node.day, node.total = state.DailyTotals[k].Day, state.DailyTotals[k].Total
+		}
+	}
+
+	// Falhas agendadas
+	s.faultsMu.Lock()
+	s.faults = nil
+	for _, state := range snapshot.Faults {
+		f := &fault{id: state.ID, sensorID: state.SensorID, config: state.Config, startAt: state.StartAt, endAt: state.EndAt}
+		if state.Held != nil {
+			f.held, f.heldSet = float64(*state.Held), true
+		}
+		s.faults = append(s.faults, f)
+	}
+	s.nextFaultID = snapshot.NextFaultID
+	s.faultsMu.Unlock()
+
+	// Posição do cenário
+	s.scenarioMu.Lock()
+	s.scenario = nil
+	if state := snapshot.Scenario; state != nil {
+		scenario := state.Scenario
+		run := &scenarioRun{
+			scenario: &scenario,
+			state:    state.State,
+			elapsed:  time.Duration(state.Elapsed),
+			lastTick: state.LastTick,
+			next:     state.Next,
+		}
+		for _, r := range state.Ramps {
+			run.ramps = append(run.ramps, &ramp{
+				sensorID: r.SensorID,
+				from:     r.From,
+				to:       r.To,
+				start:    time.Duration(r.Start),
+				duration: time.Duration(r.Duration),
+			})
+		}
+		s.scenario = run
+	}
+	s.scenarioMu.Unlock()
+
+	// Atuadores e modelos físicos
+	s.actuatorsMu.Lock()
+	for id, level := range snapshot.Actuators {
+		if a, ok := s.actuators[id]; ok {
+			a.level = level
+		}
+	}
+	s.actuatorsMu.Unlock()
+	for _, room := range snapshot.GrowRooms {
+		s.growRooms[room.ID].state = room
+	}
+	if s.sun != nil && snapshot.CloudCover != nil {
+		s.sun.setCover(*snapshot.CloudCover)
+	}
+	if s.weather != nil && snapshot.Weather != nil {
+		s.weather.restore(*snapshot.Weather)
+	}
+
+	s.readings = s.latestReadings()
+
+	// O relógio do sistema não volta ao horário salvo
+	if _, ok := s.clock.(realClock); ok {
+		s.skipToLocked(s.clock.Now())
+	}
+	return nil
+}
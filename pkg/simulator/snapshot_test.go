@@ -0,0 +1,76 @@
+package simulator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	sensors := []models.SensorConfig{
+		{ID: "ph001", Type: models.PH, Generator: &models.GeneratorConfig{Name: "dosing"}},
+		{ID: "ec001", Type: models.EC, Generator: &models.GeneratorConfig{Name: "dosing", Params: map[string]float64{"rate": 0.5}}},
+		{ID: "sub001", Type: models.SubstrateMoisture, Generator: &models.GeneratorConfig{Name: "dry_down"}},
+		{ID: "co2001", Type: models.CO2, Generator: &models.GeneratorConfig{Name: "photoperiod"}},
+		{ID: "temp001", Type: models.Temperature},
+		{ID: "dew001", Type: models.DewPoint, Expression: "dew_point(temp001, 60)"},
+	}
+
+	original := newTestSimulator(t, sensors, WithSeed(11))
+	collectSteps(t, original, 40)
+
+	// Serializar e ler o snapshot como seria gravado em arquivo
+	data, err := json.Marshal(original.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ParseSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := collectSteps(t, original, 40)
+
+	// Um simulador novo, com outra semente, retomado do snapshot continua de forma idêntica
+	restored := newTestSimulator(t, sensors, WithSeed(99))
+	if err := restored.Restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	got := collectSteps(t, restored, 40)
+	if !reflect.DeepEqual(got, want) {
+		for i := range want {
+			if !reflect.DeepEqual(got[i], want[i]) {
+				t.Fatalf("ciclo %d após a restauração diverge:\n got %+v\nwant %+v", i+1, got[i], want[i])
+			}
+		}
+		t.Fatal("leituras após a restauração divergem")
+	}
+}
+
+func TestRestoreInvalidSnapshotKeepsState(t *testing.T) {
+	sensors := testSensors(4)
+	sim := newTestSimulator(t, sensors, WithSeed(5), WithWeather(&WeatherConfig{}))
+	collectSteps(t, sim, 10)
+	before := sim.Snapshot()
+
+	other := newTestSimulator(t, sensors, WithSeed(6), WithWeather(&WeatherConfig{}))
+	collectSteps(t, other, 30)
+	for name, corrupt := range map[string]func(*Snapshot){
+		"gerador":         func(s *Snapshot) { s.Rand[len(s.Rand)-1].PCG = []byte("inválido") },
+		"regime do tempo": func(s *Snapshot) { s.Weather.Regime = "tornado" },
+	} {
+		snapshot := other.Snapshot()
+		corrupt(snapshot)
+		if err := sim.Restore(snapshot); err == nil {
+			t.Fatalf("snapshot com %s inválido aceito", name)
+		}
+
+		// Nada do snapshot rejeitado pode ter sido aplicado
+		after := sim.Snapshot()
+		after.CreatedAt = before.CreatedAt
+		if !reflect.DeepEqual(after, before) {
+			t.Fatalf("snapshot com %s inválido alterou o estado do simulador", name)
+		}
+	}
+}
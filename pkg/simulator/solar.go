@@ -0,0 +1,272 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// SiteConfig descreve o local da simulação para o modelo solar: a irradiância externa é
+// calculada pela elevação do sol e atenuada por um processo estocástico de nebulosidade
+type SiteConfig struct {
+	Latitude  float64 `json:"latitude"`  // Graus, positivo ao norte
+	Longitude float64 `json:"longitude"` // Graus, positivo a leste
+	Timezone  string  `json:"timezone"`  // Fuso IANA, ex.: America/Sao_Paulo (padrão: UTC)
+
+	CloudMean       float64 `json:"cloud_mean"`       // Cobertura média de nuvens (0 a 1, padrão: 0.3)
+	CloudReversion  float64 `json:"cloud_reversion"`  // Reversão da cobertura à média (1/h, padrão: 0.5)
+	CloudVolatility float64 `json:"cloud_volatility"` // Volatilidade da cobertura (1/√h, padrão: 0.3)
+}
+
+// SunState é o estado atual do modelo solar
+type SunState struct {
+	Elevation  float64   `json:"elevation"`   // Elevação do sol (graus)
+	ClearSky   float64   `json:"clear_sky"`   // Irradiância de céu limpo (W/m²)
+	Irradiance float64   `json:"irradiance"`  // Irradiância global com nuvens (W/m²)
+	CloudCover float64   `json:"cloud_cover"` // Cobertura de nuvens (0 a 1)
+	DayLength  float64   `json:"day_length"`  // Duração do dia (horas)
+	Sunrise    time.Time `json:"sunrise"`
+	Sunset     time.Time `json:"sunset"`
+}
+
+// sun é o modelo solar do local da simulação
+type sun struct {
+	config   SiteConfig
+	location *time.Location
+
+	mu    sync.Mutex // Protege a cobertura, lida pela API durante a simulação
+	cover float64    // Cobertura de nuvens atual, atualizada a cada ciclo
+}
+
+// WithSite define o local da simulação, habilitando os geradores "solar" e
+// "solar_temperature". Sensores de luminosidade sem gerador passam a usar o modelo solar.
+func WithSite(site *SiteConfig) Option {
+	return func(s *Simulator) {
+		s.siteConfig = site
+	}
+}
+
+// newSun cria o modelo solar do local configurado
+func newSun(config *SiteConfig) (*sun, error) {
+	if config == nil {
+		return nil, nil
+	}
+	c := *config
+	if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+		return nil, fmt.Errorf("coordenadas inválidas do local: %v, %v", c.Latitude, c.Longitude)
+	}
+	if c.CloudMean < 0 || c.CloudMean > 1 {
+		return nil, fmt.Errorf("cloud_mean deve estar entre 0 e 1, recebido %v", c.CloudMean)
+	}
+	if c.CloudReversion < 0 || c.CloudVolatility < 0 {
+		return nil, fmt.Errorf("cloud_reversion e cloud_volatility não podem ser negativos")
+	}
+
+	location := time.UTC
+	if c.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(c.Timezone); err != nil {
+			return nil, fmt.Errorf("fuso horário inválido %q: %w", c.Timezone, err)
+		}
+	}
+	if c.CloudMean == 0 {
+		c.CloudMean = 0.3
+	}
+	if c.CloudReversion == 0 {
+		c.CloudReversion = 0.5
+	}
+	if c.CloudVolatility == 0 {
+		c.CloudVolatility = 0.3
+	}
+
+	return &sun{config: c, location: location, cover: c.CloudMean}, nil
+}
+
+// solarGeometry retorna a declinação do sol (rad) e a equação do tempo (min) no dia do ano
+func solarGeometry(dayOfYear int) (declination, equationOfTime float64) {
+	declination = 23.44 * math.Pi / 180 * math.Sin(2*math.Pi/365*float64(284+dayOfYear))
+	b := 2 * math.Pi / 365 * float64(dayOfYear-81)
+	equationOfTime = 9.87*math.Sin(2*b) - 7.53*math.Cos(b) - 1.5*math.Sin(b)
+	return declination, equationOfTime
+}
+
+// elevation retorna a elevação do sol (graus) no instante informado
+func (m *sun) elevation(t time.Time) float64 {
+	utc := t.UTC()
+	declination, eot := solarGeometry(t.In(m.location).YearDay())
+
+	hours := float64(utc.Hour()) + float64(utc.Minute())/60 + float64(utc.Second())/3600
+	solarTime := hours + m.config.Longitude/15 + eot/60
+	hourAngle := (solarTime - 12) * 15 * math.Pi / 180
+
+	lat := m.config.Latitude * math.Pi / 180
+	sinElevation := math.Sin(lat)*math.Sin(declination) + math.Cos(lat)*math.Cos(declination)*math.Cos(hourAngle)
+	return math.Asin(sinElevation) * 180 / math.Pi
+}
+
+// clearSky retorna a irradiância global de céu limpo (W/m²) pelo modelo de Haurwitz
+func (m *sun) clearSky(t time.Time) float64 {
+	sinElevation := math.Sin(m.elevation(t) * math.Pi / 180)
+	if sinElevation <= 0 {
+		return 0
+	}
+	return 1098 * sinElevation * math.Exp(-0.057/sinElevation)
+}
+
+// irradiance retorna a irradiância global (W/m²) atenuada pela nebulosidade atual
+// (relação de Kasten e Czeplak)
+func (m *sun) irradiance(t time.Time) float64 {
+	return m.clearSky(t) * (1 - 0.75*math.Pow(m.cloudCover(), 3.4))
+}
+
+// cloudCover retorna a cobertura de nuvens atual
+func (m *sun) cloudCover() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cover
+}
+
+// setCover define a cobertura de nuvens atual
+func (m *sun) setCover(cover float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cover = cover
+}
+
+// step avança o processo de nebulosidade (Ornstein-Uhlenbeck limitado a [0, 1]) em
+// direção à cobertura média informada, com a discretização exata para continuar estável
+// em passos longos
+func (m *sun) step(dt time.Duration, mean float64, rng *rand.Rand) {
+	h := dt.Hours()
+	if h <= 0 {
+		return
+	}
+	c := m.config
+	decay := math.Exp(-c.CloudReversion * h)
+	stddev := c.CloudVolatility * math.Sqrt((1-decay*decay)/(2*c.CloudReversion))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cover = mean + (m.cover-mean)*decay + stddev*rng.NormFloat64()
+	m.cover = math.Max(0, math.Min(1, m.cover))
+}
+
+// state retorna o estado do modelo solar no instante informado
+func (m *sun) state(t time.Time) SunState {
+	local := t.In(m.location)
+	declination, eot := solarGeometry(local.YearDay())
+
+	// Duração do dia pelo ângulo horário do pôr do sol (limitado nos dias/noites polares)
+	lat := m.config.Latitude * math.Pi / 180
+	cosH0 := math.Max(-1, math.Min(1, -math.Tan(lat)*math.Tan(declination)))
+	dayLength := 2 * math.Acos(cosH0) * 180 / math.Pi / 15
+
+	// Meio-dia solar em UTC no dia local
+	noon := 12 - m.config.Longitude/15 - eot/60
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	hour := func(h float64) time.Time {
+		return midnight.Add(time.Duration(h * float64(time.Hour))).In(m.location)
+	}
+
+	return SunState{
+		Elevation:  m.elevation(t),
+		ClearSky:   m.clearSky(t),
+		Irradiance: m.irradiance(t),
+		CloudCover: m.cloudCover(),
+		DayLength:  dayLength,
+		Sunrise:    hour(noon - dayLength/2),
+		Sunset:     hour(noon + dayLength/2),
+	}
+}
+
+// newSolarGenerator cria o gerador de luz externa do modelo solar.
+// Parâmetros: scale (unidade do sensor por W/m², padrão: faixa do sensor por 1000 W/m²;
+// ex.: 120 para lux ao ar livre), transmission (fração que atravessa a cobertura da estufa,
+// padrão: 1), noise (padrão: noise_amplitude)
+func newSolarGenerator(model *sun, config models.SensorConfig, params map[string]float64) (Generator, error) {
+	scale := param(params, "scale", (config.MaxValue-config.MinValue)/1000)
+	transmission := param(params, "transmission", 1)
+	noise := param(params, "noise", config.NoiseAmplitude)
+	if transmission < 0 || transmission > 1 {
+		return nil, fmt.Errorf("transmission deve estar entre 0 e 1, recebido %v", transmission)
+	}
+
+	return absoluteFunc(func(ctx *GeneratorContext) float64 {
+		irradiance := model.irradiance(ctx.Now)
+		if irradiance <= 0 {
+			return config.MinValue
+		}
+		return config.MinValue + irradiance*transmission*scale + uniformNoise(ctx.Rand, noise)
+	}), nil
+}
+
+// solarTemperature segue o aquecimento solar com atraso de primeira ordem, o que coloca
+// a máxima do dia no meio da tarde
+type solarTemperature struct {
+	model   *sun
+	weather *weather // Modelo do tempo (nil = sem desvio de regime)
+	night   float64  // Temperatura sem sol (°C)
+	gain    float64  // Aquecimento por 1000 W/m² (°C)
+	tau     float64  // Constante de tempo (h)
+	noise   float64
+	state   measured
+}
+
+// Next calcula a nova temperatura aproximando-a do alvo dado pela irradiância atual
+func (g *solarTemperature) Next(ctx *GeneratorContext) float64 {
+	value := g.state.base(ctx.LastValue)
+	target := g.night + g.gain*g.model.irradiance(ctx.Now)/1000
+	if g.weather != nil {
+		target += g.weather.currentEffect().Temperature
+	}
+	value += (target - value) * (1 - math.Exp(-ctx.Step.Hours()/g.tau))
+	return g.state.report(value, uniformNoise(ctx.Rand, g.noise))
+}
+
+// saveState e loadState implementam statefulGenerator
+func (g *solarTemperature) saveState() GeneratorState      { return g.state.save() }
+func (g *solarTemperature) loadState(state GeneratorState) { g.state.load(state) }
+
+// newSolarTemperatureGenerator cria o gerador de temperatura que segue o modelo solar.
+// Parâmetros: night (°C, padrão: mínimo do sensor + 20% da faixa), gain (°C por 1000 W/m²,
+// padrão: 60% da faixa), tau (horas, padrão: 2), noise (padrão: noise_amplitude)
+func newSolarTemperatureGenerator(model *sun, weather *weather, config models.SensorConfig, params map[string]float64) (Generator, error) {
+	span := config.MaxValue - config.MinValue
+	g := &solarTemperature{
+		model:   model,
+		weather: weather,
+		night:   param(params, "night", config.MinValue+span*0.2),
+		gain:    param(params, "gain", span*0.6),
+		tau:     param(params, "tau", 2),
+		noise:   param(params, "noise", config.NoiseAmplitude),
+	}
+	if g.tau <= 0 {
+		return nil, fmt.Errorf("tau deve ser positivo, recebido %v", g.tau)
+	}
+	return g, nil
+}
+
+// stepSun avança a nebulosidade do modelo solar. Com o modelo do tempo habilitado, a
+// cobertura média é a do regime atual.
+func (s *Simulator) stepSun(dt time.Duration) {
+	if s.sun == nil {
+		return
+	}
+	mean := s.sun.config.CloudMean
+	if s.weather != nil {
+		mean = s.weather.currentEffect().CloudCover
+	}
+	s.sun.step(dt, mean, s.rng)
+}
+
+// Sun retorna o estado atual do modelo solar, ou nil se nenhum local foi configurado
+func (s *Simulator) Sun() *SunState {
+	if s.sun == nil {
+		return nil
+	}
+	state := s.sun.state(s.clock.Now())
+	return &state
+}
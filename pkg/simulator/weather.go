@@ -0,0 +1,411 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"go-sensors-simulator/pkg/models"
+)
+
+// WeatherConfig configura o modelo de regimes do tempo: uma cadeia de Markov em que cada
+// regime dura um tempo sorteado entre MinDwell e MaxDwell e, ao terminar, passa para o
+// próximo regime conforme as probabilidades de transição
+type WeatherConfig struct {
+	Initial    string          `json:"initial,omitempty"`    // Regime inicial (padrão: primeiro da lista)
+	Transition models.Duration `json:"transition,omitempty"` // Constante de tempo da mudança entre regimes (padrão: 2h)
+	Regimes    []WeatherRegime `json:"regimes,omitempty"`    // Regimes (padrão: clear, cloudy, rainy_front, heat_wave)
+}
+
+// WeatherRegime descreve um regime do tempo e seus efeitos sobre os sensores ambientais
+type WeatherRegime struct {
+	Name        string             `json:"name"`
+	MinDwell    models.Duration    `json:"min_dwell"`   // Duração mínima do regime
+	MaxDwell    models.Duration    `json:"max_dwell"`   // Duração máxima do regime
+	Transitions map[string]float64 `json:"transitions"` // Peso de cada regime seguinte (normalizado)
+
+	Temperature float64 `json:"temperature"` // Desvio da temperatura (°C)
+	Humidity    float64 `json:"humidity"`    // Desvio da umidade (%)
+	Pressure    float64 `json:"pressure"`    // Desvio da pressão (hPa)
+	Light       float64 `json:"light"`       // Fração da luz de céu limpo (0 a 1)
+	CloudCover  float64 `json:"cloud_cover"` // Cobertura média de nuvens do modelo solar (0 a 1)
+}
+
+// defaultWeatherRegimes são os regimes usados quando a configuração não define nenhum
+var defaultWeatherRegimes = []WeatherRegime{
+	{
+		Name: "clear", MinDwell: hours(12), MaxDwell: hours(48),
+		Transitions: map[string]float64{"cloudy": 0.6, "heat_wave": 0.15, "rainy_front": 0.25},
+		Temperature: 1, Humidity: -5, Pressure: 4, Light: 1, CloudCover: 0.1,
+	},
+	{
+		Name: "cloudy", MinDwell: hours(6), MaxDwell: hours(24),
+		Transitions: map[string]float64{"clear": 0.5, "rainy_front": 0.4, "heat_wave": 0.1},
+		Temperature: -1, Humidity: 5, Pressure: -2, Light: 0.5, CloudCover: 0.6,
+	},
+	{
+		Name: "rainy_front", MinDwell: hours(3), MaxDwell: hours(12),
+		Transitions: map[string]float64{"cloudy": 0.6, "clear": 0.4},
+		Temperature: -4, Humidity: 20, Pressure: -10, Light: 0.2, CloudCover: 0.95,
+	},
+	{
+		Name: "heat_wave", MinDwell: hours(48), MaxDwell: hours(120),
+		Transitions: map[string]float64{"clear": 0.7, "rainy_front": 0.3},
+		Temperature: 6, Humidity: -15, Pressure: 2, Light: 1, CloudCover: 0.05,
+	},
+}
+
+// hours converte horas em models.Duration
+func hours(h float64) models.Duration {
+	return models.Duration(time.Duration(h * float64(time.Hour)))
+}
+
+// WeatherEffect é o efeito combinado do tempo sobre os sensores ambientais, que acompanha
+// o regime atual com atraso de primeira ordem para que as mudanças não sejam degraus
+type WeatherEffect struct {
+	Temperature float64 `json:"temperature"`
+	Humidity    float64 `json:"humidity"`
+	Pressure    float64 `json:"pressure"`
+	Light       float64 `json:"light"`
+	CloudCover  float64 `json:"cloud_cover"`
+}
+
+// offset retorna o desvio aditivo do tipo de sensor
+func (e WeatherEffect) offset(t models.SensorType) float64 {
+	switch t {
+	case models.Temperature:
+		return e.Temperature
+	case models.Humidity:
+		return e.Humidity
+	case models.Pressure:
+		return e.Pressure
+	}
+	return 0
+}
+
+// WeatherState é o estado atual do modelo do tempo
+type WeatherState struct {
+	Regime string        `json:"regime"`
+	Since  time.Time     `json:"since"` // Início do regime atual
+	Until  time.Time     `json:"until"` // Fim previsto do regime atual
+	Effect WeatherEffect `json:"effect"`
+}
+
+// weather é o modelo de regimes do tempo da simulação
+type weather struct {
+	regimes    map[string]*WeatherRegime
+	initial    string
+	transition time.Duration
+
+	mu      sync.Mutex // Protege o estado, alterado pela API durante a simulação
+	current *WeatherRegime
+	since   time.Time
+	until   time.Time
+	effect  WeatherEffect
+}
+
+// WithWeather habilita o modelo de regimes do tempo. Sensores de temperatura, umidade,
+// pressão e luminosidade sem gerador passam a usar o gerador "weather".
+func WithWeather(config *WeatherConfig) Option {
+	return func(s *Simulator) {
+		s.weatherConfig = config
+	}
+}
+
+// newWeather valida a configuração e cria o modelo do tempo
+func newWeather(config *WeatherConfig) (*weather, error) {
+	if config == nil {
+		return nil, nil
+	}
+	regimes := config.Regimes
+	if len(regimes) == 0 {
+		regimes = defaultWeatherRegimes
+	}
+
+	w := &weather{
+		regimes:    make(map[string]*WeatherRegime, len(regimes)),
+		initial:    config.Initial,
+		transition: time.Duration(config.Transition),
+	}
+	if w.transition < 0 {
+		return nil, fmt.Errorf("transition não pode ser negativo, recebido %v", w.transition)
+	}
+	if w.transition == 0 {
+		w.transition = 2 * time.Hour
+	}
+	if w.initial == "" {
+		w.initial = regimes[0].Name
+	}
+
+	for i := range regimes {
+		regime := regimes[i]
+		if regime.Name == "" {
+			return nil, fmt.Errorf("regime do tempo sem nome")
+		}
+		if _, exists := w.regimes[regime.Name]; exists {
+			return nil, fmt.Errorf("regime do tempo duplicado: %s", regime.Name)
+		}
+		if regime.MinDwell <= 0 || regime.MaxDwell < regime.MinDwell {
+			return nil, fmt.Errorf("duração inválida do regime %s: min_dwell deve ser positivo e max_dwell não menor que min_dwell", regime.Name)
+		}
+		if regime.Light < 0 || regime.Light > 1 || regime.CloudCover < 0 || regime.CloudCover > 1 {
+			return nil, fmt.Errorf("light e cloud_cover do regime %s devem estar entre 0 e 1", regime.Name)
+		}
+		w.regimes[regime.Name] = &regime
+	}
+
+	for _, regime := range w.regimes {
+		total := 0.0
+		for next, p := range regime.Transitions {
+			if _, ok := w.regimes[next]; !ok {
+				return nil, fmt.Errorf("o regime %s tem transição para o regime desconhecido %q", regime.Name, next)
+			}
+			if p < 0 {
+				return nil, fmt.Errorf("probabilidade negativa na transição %s -> %s", regime.Name, next)
+			}
+			total += p
+		}
+		if total == 0 && len(w.regimes) > 1 {
+			return nil, fmt.Errorf("o regime %s não tem transições", regime.Name)
+		}
+	}
+	if _, ok := w.regimes[w.initial]; !ok {
+		return nil, fmt.Errorf("regime inicial desconhecido: %s", w.initial)
+	}
+	return w, nil
+}
+
+// reset volta ao regime inicial no instante informado, com o efeito já estabilizado
+func (w *weather) reset(now time.Time, rng *rand.Rand) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.enter(w.regimes[w.initial], now, rng)
+	w.effect = w.current.effect()
+}
+
+// enter inicia o regime e sorteia a sua duração
+func (w *weather) enter(regime *WeatherRegime, at time.Time, rng *rand.Rand) {
+	dwell := time.Duration(regime.MinDwell)
+	if spread := regime.MaxDwell - regime.MinDwell; spread > 0 {
+		dwell += time.Duration(rng.Float64() * float64(spread))
+	}
+	w.current = regime
+	w.since = at
+	w.until = at.Add(dwell)
+}
+
+// next sorteia o regime seguinte conforme as probabilidades de transição
+func (w *weather) next(rng *rand.Rand) *WeatherRegime {
+	// Ordenar os nomes para que o sorteio seja reproduzível com a mesma semente
+	names := make([]string, 0, len(w.current.Transitions))
+	total := 0.0
+	for name, p := range w.current.Transitions {
+		names = append(names, name)
+		total += p
+	}
+	if total == 0 {
+		return w.current
+	}
+	sort.Strings(names)
+
+	r := rng.Float64() * total
+	for _, name := range names {
+		r -= w.current.Transitions[name]
+		if r < 0 {
+			return w.regimes[name]
+		}
+	}
+	return w.regimes[names[len(names)-1]]
+}
+
+// step executa as transições vencidas até now e aproxima o efeito do regime atual
+func (w *weather) step(now time.Time, dt time.Duration, rng *rand.Rand) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for !w.until.After(now) {
+		w.enter(w.next(rng), w.until, rng)
+	}
+
+	if dt <= 0 {
+		return
+	}
+	target := w.current.effect()
+	k := 1 - math.Exp(-dt.Seconds()/w.transition.Seconds())
+	w.effect.Temperature += (target.Temperature - w.effect.Temperature) * k
+	w.effect.Humidity += (target.Humidity - w.effect.Humidity) * k
+	w.effect.Pressure += (target.Pressure - w.effect.Pressure) * k
+	w.effect.Light += (target.Light - w.effect.Light) * k
+	w.effect.CloudCover += (target.CloudCover - w.effect.CloudCover) * k
+}
+
+// effect retorna o efeito pleno do regime
+func (r *WeatherRegime) effect() WeatherEffect {
+	return WeatherEffect{
+		Temperature: r.Temperature,
+		Humidity:    r.Humidity,
+		Pressure:    r.Pressure,
+		Light:       r.Light,
+		CloudCover:  r.CloudCover,
+	}
+}
+
+// currentEffect retorna o efeito atual do tempo
+func (w *weather) currentEffect() WeatherEffect {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.effect
+}
+
+// force muda para o regime informado imediatamente, com a duração média do regime
+func (w *weather) force(name string, now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	regime, ok := w.regimes[name]
+	if !ok {
+		return fmt.Errorf("regime do tempo desconhecido: %s", name)
+	}
+	w.current = regime
+	w.since = now
+	w.until = now.Add(time.Duration(regime.MinDwell+regime.MaxDwell) / 2)
+	return nil
+}
+
+// state retorna o estado atual do modelo do tempo
+func (w *weather) state() WeatherState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WeatherState{Regime: w.current.Name, Since: w.since, Until: w.until, Effect: w.effect}
+}
+
+// validate verifica se um estado salvo pode ser retomado por este modelo
+func (w *weather) validate(state WeatherState) error {
+	if _, ok := w.regimes[state.Regime]; !ok {
+		return fmt.Errorf("regime do tempo desconhecido: %s", state.Regime)
+	}
+	return nil
+}
+
+// restore retoma o modelo a partir de um estado salvo, já verificado por validate
+func (w *weather) restore(state WeatherState) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.current = w.regimes[state.Regime]
+	w.since, w.until = state.Since, state.Until
+	w.effect = state.Effect
+}
+
+// weatherGenerator combina o valor médio, o ciclo diário e o efeito do regime do tempo.
+// Como o valor não depende do anterior, não há deriva acumulada ao longo do tempo.
+type weatherGenerator struct {
+	model   *weather
+	mean    float64
+	diurnal func(hourOfDay float64) float64
+	light   bool // O ciclo diário é multiplicado pela fração de luz do regime
+	noise   float64
+}
+
+// Next calcula o valor do horário atual sob o regime do tempo atual
+func (g *weatherGenerator) Next(ctx *GeneratorContext) float64 {
+	effect := g.model.currentEffect()
+	hourOfDay := float64(ctx.Now.Hour()) + float64(ctx.Now.Minute())/60 + float64(ctx.Now.Second())/3600
+
+	value := g.mean + effect.offset(ctx.Config.Type)
+	if g.light {
+		value += g.diurnal(hourOfDay) * effect.Light
+	} else {
+		value += g.diurnal(hourOfDay)
+	}
+	return value + uniformNoise(ctx.Rand, g.noise)
+}
+
+// absolute marca o gerador como absoluto: o valor não parte do último valor do sensor
+func (g *weatherGenerator) absolute() {}
+
+// newWeatherGenerator cria o gerador ambiental modulado pelo regime do tempo.
+// Parâmetros: mean (padrão: meio da faixa; mínimo da faixa para luminosidade),
+// amplitude do ciclo diário (padrão: 15% da faixa; 0.5 hPa para pressão; 400 para luz),
+// peak_hour (padrão: 15), sunrise e sunset para luminosidade (padrão: 5 e 19), noise (padrão: noise_amplitude)
+func newWeatherGenerator(model *weather, config models.SensorConfig, params map[string]float64) (Generator, error) {
+	span := config.MaxValue - config.MinValue
+	g := &weatherGenerator{
+		model: model,
+		mean:  param(params, "mean", midpoint(config)),
+		noise: param(params, "noise", config.NoiseAmplitude),
+	}
+	peakHour := param(params, "peak_hour", 15)
+
+	switch config.Type {
+	case models.Temperature:
+		amplitude := param(params, "amplitude", span*0.15)
+		g.diurnal = func(h float64) float64 {
+			return amplitude * math.Cos((h-peakHour)/24*2*math.Pi)
+		}
+	case models.Humidity:
+		// Umidade relativa mínima no horário mais quente
+		amplitude := param(params, "amplitude", span*0.15)
+		g.diurnal = func(h float64) float64 {
+			return -amplitude * math.Cos((h-peakHour)/24*2*math.Pi)
+		}
+	case models.Pressure:
+		// Maré barométrica semidiurna, com máximas por volta das 10h e 22h
+		amplitude := param(params, "amplitude", 0.5)
+		g.diurnal = func(h float64) float64 {
+			return amplitude * math.Cos((h-10)/12*2*math.Pi)
+		}
+	case models.Light:
+		amplitude := param(params, "amplitude", 400)
+		sunrise := param(params, "sunrise", 5)
+		sunset := param(params, "sunset", 19)
+		if sunset <= sunrise {
+			return nil, fmt.Errorf("sunset deve ser maior que sunrise")
+		}
+		g.mean = param(params, "mean", config.MinValue)
+		g.light = true
+		g.diurnal = func(h float64) float64 {
+			if h < sunrise || h > sunset {
+				return 0
+			}
+			return amplitude * math.Sin((h-sunrise)/(sunset-sunrise)*math.Pi)
+		}
+	default:
+		return nil, fmt.Errorf("tipo de sensor não suportado: %s", config.Type)
+	}
+	return g, nil
+}
+
+// weatherTypes são os tipos de sensor que usam o gerador "weather" por padrão
+var weatherTypes = map[models.SensorType]bool{
+	models.Temperature: true,
+	models.Humidity:    true,
+	models.Pressure:    true,
+	models.Light:       true,
+}
+
+// stepWeather executa as transições de regime e atualiza o efeito do tempo
+func (s *Simulator) stepWeather(now time.Time, dt time.Duration) {
+	if s.weather != nil {
+		s.weather.step(now, dt, s.rng)
+	}
+}
+
+// Weather retorna o estado atual do modelo do tempo, ou nil se não estiver habilitado
+func (s *Simulator) Weather() *WeatherState {
+	if s.weather == nil {
+		return nil
+	}
+	state := s.weather.state()
+	return &state
+}
+
+// SetWeatherRegime muda o regime do tempo imediatamente, com a duração média do regime.
+// O efeito acompanha o novo regime com a constante de tempo de transição configurada.
+func (s *Simulator) SetWeatherRegime(name string) error {
+	if s.weather == nil {
+		return fmt.Errorf("o modelo do tempo não está habilitado")
+	}
+	return s.weather.force(name, s.clock.Now())
+}
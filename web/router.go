@@ -2,18 +2,30 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"go-sensors-simulator/configs"
+	"go-sensors-simulator/pkg/models"
 	"go-sensors-simulator/pkg/simulator"
 	"go-sensors-simulator/web/templates"
 )
 
+// ReadingsSource fornece as leituras mais recentes exibidas pela API e pelo dashboard
+type ReadingsSource interface {
+	GetReadings() []models.SensorReading
+}
+
 // Router gerencia as rotas HTTP
 type Router struct {
 	simulator       *simulator.Simulator
+	readings        ReadingsSource
 	config          configs.AppConfig
 	templateHandler *templates.Handler
 }
@@ -22,11 +34,17 @@ type Router struct {
 func NewRouter(sim *simulator.Simulator, config configs.AppConfig) *Router {
 	return &Router{
 		simulator:       sim,
+		readings:        sim,
 		config:          config,
 		templateHandler: templates.NewHandler(sim, config),
 	}
 }
 
+// SetReadingsSource substitui a fonte das leituras (ex.: reprodução de CSV)
+func (r *Router) SetReadingsSource(source ReadingsSource) {
+	r.readings = source
+}
+
 // ServeHTTP implementa a interface http.Handler
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// Configurar headers básicos para todas as respostas
@@ -37,12 +55,48 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	case "/", "/index.html", "/dashboard":
 		r.templateHandler.HandleDashboard(w, req)
 	case "/api/sensors":
-		r.handleAPIGetSensors(w, req)
+		r.handleAPISensors(w, req)
 	case "/api/readings":
 		r.handleAPIGetReadings(w, req)
 	case "/api/reset-simulation":
 		r.handleAPIResetSimulation(w, req)
+	case "/api/faults":
+		r.handleAPIFaults(w, req)
+	case "/api/time":
+		r.handleAPITime(w, req)
+	case "/api/sun":
+		r.handleAPISun(w, req)
+	case "/api/weather":
+		r.handleAPIWeather(w, req)
+	case "/api/actuators":
+		r.handleAPIActuators(w, req)
+	case "/api/stream":
+		r.handleAPIStream(w, req)
+	case "/api/subscribers":
+		r.handleAPISubscribers(w, req)
+	case "/api/snapshot":
+		r.handleAPISnapshot(w, req)
+	case "/api/simulation":
+		r.handleAPISimulation(w, req)
+	case "/api/simulation/pause":
+		r.handleAPISimulationControl(w, req, r.simulator.Pause)
+	case "/api/simulation/resume":
+		r.handleAPISimulationControl(w, req, r.simulator.Resume)
+	case "/api/simulation/step":
+		r.handleAPISimulationControl(w, req, r.simulator.Step)
+	case "/api/scenario":
+		r.handleAPIScenario(w, req)
+	case "/api/scenario/start":
+		r.handleAPIScenarioControl(w, req, r.simulator.StartScenario)
+	case "/api/scenario/pause":
+		r.handleAPIScenarioControl(w, req, r.simulator.PauseScenario)
 	default:
+		// Sensor individual: /api/sensors/{id}
+		if id, ok := strings.CutPrefix(req.URL.Path, "/api/sensors/"); ok && id != "" {
+			r.handleAPISensor(w, req, id)
+			return
+		}
+
 		// Verificar se está tentando acessar um recurso estático
 		if req.URL.Path == "/static/" || filepath.HasPrefix(req.URL.Path, "/static/") {
 			// Servir arquivos estáticos
@@ -62,14 +116,107 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-// handleAPIGetSensors retorna a lista de sensores em formato JSON
-func (r *Router) handleAPIGetSensors(w http.ResponseWriter, req *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+// handleAPISensors retorna a lista de sensores (GET) ou adiciona um sensor (POST)
+func (r *Router) handleAPISensors(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
 
-	// Serializar sensores como JSON
-	if err := json.NewEncoder(w).Encode(r.config.Sensors); err != nil {
-		log.Printf("Erro ao serializar sensores para JSON: %v", err)
-		http.Error(w, "Erro interno do servidor", http.StatusInternalServerError)
+		// Filtrar pela hierarquia de ativos (?site=&room=&device=&sensor=) e por etiqueta (?tag=)
+		filter := assetFilter(req)
+		tag := req.URL.Query().Get("tag")
+		sensors := make([]models.SensorConfig, 0)
+		for _, sensor := range r.simulator.Configs() {
+			if filter.Match(sensor.ID, sensor.Path()) && (tag == "" || sensor.HasTag(tag)) {
+				sensors = append(sensors, sensor)
+			}
+		}
+
+		// Serializar sensores como JSON
+		if err := json.NewEncoder(w).Encode(sensors); err != nil {
+			log.Printf("Erro ao serializar sensores para JSON: %v", err)
+			http.Error(w, "Erro interno do servidor", http.StatusInternalServerError)
+		}
+	case http.MethodPost:
+		r.handleAPIAddSensor(w, req, "")
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPISensor consulta (GET), adiciona (POST), substitui (PUT) ou remove (DELETE)
+// um sensor da simulação em execução
+func (r *Router) handleAPISensor(w http.ResponseWriter, req *http.Request, id string) {
+	switch req.Method {
+	case http.MethodGet:
+		sensor, err := r.simulator.Sensor(id)
+		if err != nil {
+			writeSensorError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sensor)
+
+	case http.MethodPost:
+		r.handleAPIAddSensor(w, req, id)
+
+	case http.MethodPut:
+		var sensor models.SensorConfig
+		if err := json.NewDecoder(req.Body).Decode(&sensor); err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+		sensor, err := r.simulator.UpdateSensor(id, sensor)
+		if err != nil {
+			writeSensorError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sensor)
+
+	case http.MethodDelete:
+		if err := r.simulator.RemoveSensor(id); err != nil {
+			writeSensorError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "success", "message": "Sensor removido"})
+
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPIAddSensor adiciona o sensor do corpo da requisição. Se o caminho tiver um id,
+// ele prevalece sobre um id omitido no corpo.
+func (r *Router) handleAPIAddSensor(w http.ResponseWriter, req *http.Request, id string) {
+	var sensor models.SensorConfig
+	if err := json.NewDecoder(req.Body).Decode(&sensor); err != nil {
+		http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+		return
+	}
+	if id != "" {
+		if sensor.ID != "" && sensor.ID != id {
+			http.Error(w, "O id do corpo difere do id do caminho", http.StatusBadRequest)
+			return
+		}
+		sensor.ID = id
+	}
+
+	sensor, err := r.simulator.AddSensor(sensor)
+	if err != nil {
+		writeSensorError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sensor)
+}
+
+// writeSensorError responde com o status correspondente ao erro de uma operação de sensor
+func writeSensorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, simulator.ErrSensorNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, simulator.ErrSensorExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
 	}
 }
 
@@ -77,8 +224,8 @@ func (r *Router) handleAPIGetSensors(w http.ResponseWriter, req *http.Request) {
 func (r *Router) handleAPIGetReadings(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Obter leituras do simulador
-	readings := r.simulator.GetReadings()
+	// Obter leituras da fonte configurada, filtradas pela hierarquia de ativos
+	readings := filterReadings(r.readings.GetReadings(), assetFilter(req))
 
 	// Serializar leituras como JSON
 	if err := json.NewEncoder(w).Encode(readings); err != nil {
@@ -87,6 +234,42 @@ func (r *Router) handleAPIGetReadings(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// readingFilter seleciona sensores por qualquer nível da hierarquia de ativos, do local ao
+// próprio sensor; níveis omitidos aceitam qualquer valor
+type readingFilter struct {
+	models.AssetPath
+	Sensor string
+}
+
+// Match indica se o sensor, com o caminho informado, atende ao filtro
+func (f readingFilter) Match(sensorID string, path models.AssetPath) bool {
+	return (f.Sensor == "" || f.Sensor == sensorID) && path.Match(f.AssetPath)
+}
+
+// assetFilter lê da consulta os níveis da hierarquia de ativos usados como filtro
+// (?site=&room=&device=&sensor=)
+func assetFilter(req *http.Request) readingFilter {
+	query := req.URL.Query()
+	return readingFilter{
+		AssetPath: models.AssetPath{Site: query.Get("site"), Room: query.Get("room"), Device: query.Get("device")},
+		Sensor:    query.Get("sensor"),
+	}
+}
+
+// filterReadings retorna as leituras dos sensores que atendem ao filtro de ativos
+func filterReadings(readings []models.SensorReading, filter readingFilter) []models.SensorReading {
+	if filter == (readingFilter{}) {
+		return readings
+	}
+	filtered := make([]models.SensorReading, 0, len(readings))
+	for _, reading := range readings {
+		if filter.Match(reading.SensorID, reading.Path()) {
+			filtered = append(filtered, reading)
+		}
+	}
+	return filtered
+}
+
 // handleAPIResetSimulation reseta a simulação com novos valores aleatórios
 func (r *Router) handleAPIResetSimulation(w http.ResponseWriter, req *http.Request) {
 	// Verificar se é uma requisição POST
@@ -95,14 +278,352 @@ func (r *Router) handleAPIResetSimulation(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
+	// Ler semente opcional do corpo da requisição
+	var body struct {
+		Seed int64 `json:"seed"`
+	}
+	if req.Body != nil {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil && err != io.EOF {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Resetar a simulação
-	r.simulator.ResetSimulation()
+	seed, err := r.simulator.ResetSimulation(body.Seed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Retornar resposta de sucesso
 	w.Header().Set("Content-Type", "application/json")
-	response := map[string]string{"status": "success", "message": "Simulação resetada com sucesso"}
+	response := map[string]interface{}{"status": "success", "message": "Simulação resetada com sucesso", "seed": seed}
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Erro ao serializar resposta para JSON: %v", err)
 		http.Error(w, "Erro interno do servidor", http.StatusInternalServerError)
 	}
 }
+
+// handleAPIFaults lista (GET), agenda (POST) ou remove (DELETE) falhas injetadas nos sensores
+func (r *Router) handleAPIFaults(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, r.simulator.Faults())
+
+	case http.MethodPost:
+		var body struct {
+			SensorID string `json:"sensor_id"`
+			models.FaultConfig
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+
+		id, err := r.simulator.AddFault(body.SensorID, body.FaultConfig)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "success", "id": id})
+
+	case http.MethodDelete:
+		// Sem id, todas as falhas são removidas
+		idParam := req.URL.Query().Get("id")
+		if idParam == "" {
+			r.simulator.ClearFaults()
+			writeJSON(w, http.StatusOK, map[string]string{"status": "success", "message": "Falhas removidas"})
+			return
+		}
+
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			http.Error(w, "Identificador de falha inválido", http.StatusBadRequest)
+			return
+		}
+		if !r.simulator.RemoveFault(id) {
+			http.Error(w, "Falha não encontrada", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "success", "message": "Falha removida"})
+
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPITime retorna (GET) ou altera (POST) a velocidade do tempo simulado
+func (r *Router) handleAPITime(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var body struct {
+			Speed float64 `json:"speed"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+		if err := r.simulator.SetTimeSpeed(body.Speed); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"now":   r.simulator.Now(),
+		"speed": r.simulator.TimeSpeed(),
+	})
+}
+
+// handleAPISun retorna o estado do modelo solar do local da simulação
+func (r *Router) handleAPISun(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	state := r.simulator.Sun()
+	if state == nil {
+		http.Error(w, "Nenhum local configurado", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleAPIWeather retorna o regime do tempo atual (GET) ou força um novo regime (POST)
+func (r *Router) handleAPIWeather(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var body struct {
+			Regime string `json:"regime"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+		if err := r.simulator.SetWeatherRegime(body.Regime); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := r.simulator.Weather()
+	if state == nil {
+		http.Error(w, "Modelo do tempo não habilitado", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleAPIActuators retorna o estado dos atuadores (GET) ou comanda um atuador (POST)
+func (r *Router) handleAPIActuators(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, r.simulator.Actuators())
+
+	case http.MethodPost:
+		var body struct {
+			ActuatorID string  `json:"actuator_id"`
+			Value      float64 `json:"value"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+		if err := r.simulator.SetActuator(body.ActuatorID, body.Value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, r.simulator.Actuators())
+
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPIScenario retorna o progresso do cenário (GET) ou carrega um novo cenário
+// em JSON ou YAML (POST)
+func (r *Router) handleAPIScenario(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		status := r.simulator.ScenarioStatus()
+		if status == nil {
+			http.Error(w, "Nenhum cenário carregado", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	case http.MethodPost:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+		scenario, err := simulator.ParseScenario(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := r.simulator.LoadScenario(scenario); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, r.simulator.ScenarioStatus())
+
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPIScenarioControl executa uma ação de controle do cenário (iniciar/pausar)
+func (r *Router) handleAPIScenarioControl(w http.ResponseWriter, req *http.Request, action func() error) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := action(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, r.simulator.ScenarioStatus())
+}
+
+// handleAPISimulation retorna o estado de execução do simulador
+func (r *Router) handleAPISimulation(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, r.simulator.Status())
+}
+
+// handleAPISnapshot baixa (GET) ou restaura (POST) um snapshot do estado da simulação
+func (r *Router) handleAPISnapshot(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		snapshot := r.simulator.Snapshot()
+		filename := fmt.Sprintf("snapshot-%s.json", snapshot.Now.UTC().Format("20060102-150405"))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		writeJSON(w, http.StatusOK, snapshot)
+
+	case http.MethodPost:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, "Corpo da requisição inválido", http.StatusBadRequest)
+			return
+		}
+		snapshot, err := simulator.ParseSnapshot(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := r.simulator.Restore(snapshot); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, r.simulator.Status())
+
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPISimulationControl executa uma ação de controle do simulador (pausar/retomar/avançar)
+func (r *Router) handleAPISimulationControl(w http.ResponseWriter, req *http.Request, action func() error) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := action(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, r.simulator.Status())
+}
+
+// streamSubscriber é a fila de cada cliente de /api/stream: um cliente lento perde os
+// ciclos mais antigos, sem atrasar a simulação
+var streamSubscriber = simulator.SubscriberConfig{Buffer: 16, Overflow: simulator.DropOldest}
+
+// handleAPIStream envia as leituras de cada ciclo como Server-Sent Events
+func (r *Router) handleAPIStream(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming não suportado", http.StatusInternalServerError)
+		return
+	}
+
+	filter := assetFilter(req)
+	cycles := make(chan []models.SensorReading, 1)
+	closed := make(chan struct{})
+	subscription, err := r.simulator.Subscribe("web:"+req.RemoteAddr, streamSubscriber, func(readings []models.SensorReading) {
+		readings = filterReadings(readings, filter)
+		if len(readings) == 0 {
+			return
+		}
+		select {
+		case cycles <- readings:
+		case <-closed:
+		}
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Liberar o callback antes de cancelar a assinatura, que aguarda a fila esvaziar
+	defer subscription.Unsubscribe()
+	defer close(closed)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case readings := <-cycles:
+			data, err := json.Marshal(readings)
+			if err != nil {
+				log.Printf("Erro ao serializar leituras para JSON: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleAPISubscribers retorna o estado da fila de cada assinante das leituras
+func (r *Router) handleAPISubscribers(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, r.simulator.Subscribers())
+}
+
+// writeJSON serializa a resposta como JSON com o status informado
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Erro ao serializar resposta para JSON: %v", err)
+	}
+}
@@ -1,24 +1,22 @@
 // Configurações globais
 const UPDATE_INTERVAL = 2000; // 2 segundos
 const MAX_DATA_POINTS = 100;  // Máximo de pontos nos gráficos
-const CHART_COLORS = {
-    temperature: 'rgb(255, 99, 132)',
-    humidity: 'rgb(54, 162, 235)',
-    light: 'rgb(255, 205, 86)',
-    pressure: 'rgb(75, 192, 192)'
-};
+const CHART_COLORS = [
+    'rgb(255, 99, 132)',
+    'rgb(54, 162, 235)',
+    'rgb(255, 205, 86)',
+    'rgb(75, 192, 192)',
+    'rgb(153, 102, 255)',
+    'rgb(255, 159, 64)',
+    'rgb(25, 135, 84)',
+    'rgb(201, 203, 207)'
+];
 
-// Armazenamento de dados
-const sensorData = {
-    temperature: [],
-    humidity: [],
-    light: [],
-    pressure: []
-};
+// Armazenamento de dados por sensor: { type, unit, points }
+const sensorData = {};
 
-// Inicialização de gráficos
-let tempHumidChart;
-let lightPressureChart;
+// Inicialização de gráficos: um gráfico por tipo de sensor, criados a partir do template
+const typeCharts = {};
 let historyChart;
 
 // Função para resetar a simulação
@@ -42,8 +40,8 @@ async function resetSimulation() {
         }
         
         // Limpar os dados armazenados
-        Object.keys(sensorData).forEach(key => {
-            sensorData[key] = [];
+        Object.values(sensorData).forEach(sensor => {
+            sensor.points = [];
         });
         
         // Atualizar gráficos
@@ -70,290 +68,153 @@ async function resetSimulation() {
     }
 }
 
-// Função para inicializar gráficos
-function initCharts() {
-    // Configuração para o gráfico de temperatura e umidade
-    const tempHumidCtx = document.getElementById('temp-humid-chart').getContext('2d');
-    tempHumidChart = new Chart(tempHumidCtx, {
-        type: 'line',
-        data: {
-            datasets: [
-                {
-                    label: 'Temperatura (°C)',
-                    borderColor: CHART_COLORS.temperature,
-                    backgroundColor: CHART_COLORS.temperature + '20',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    fill: true,
-                    data: []
-                },
-                {
-                    label: 'Umidade (%)',
-                    borderColor: CHART_COLORS.humidity,
-                    backgroundColor: CHART_COLORS.humidity + '20',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    fill: true,
-                    data: []
-                }
-            ]
+// Opções comuns dos gráficos de linha
+function chartOptions(yTitle) {
+    return {
+        responsive: true,
+        maintainAspectRatio: false,
+        interaction: {
+            mode: 'index',
+            intersect: false
         },
-        options: {
-            responsive: true,
-            maintainAspectRatio: false,
-            interaction: {
-                mode: 'index',
-                intersect: false
-            },
-            scales: {
-                x: {
-                    type: 'time',
-                    time: {
-                        unit: 'minute',
-                        displayFormats: {
-                            minute: 'HH:mm:ss'
-                        }
-                    },
-                    title: {
-                        display: true,
-                        text: 'Hora'
+        scales: {
+            x: {
+                type: 'time',
+                time: {
+                    unit: 'minute',
+                    displayFormats: {
+                        minute: 'HH:mm:ss'
                     }
                 },
-                y: {
-                    title: {
-                        display: true,
-                        text: 'Valor'
-                    }
+                title: {
+                    display: true,
+                    text: 'Hora'
                 }
             },
-            plugins: {
-                legend: {
-                    position: 'top',
-                },
-                tooltip: {
-                    callbacks: {
-                        label: function(context) {
-                            let label = context.dataset.label || '';
-                            if (label) {
-                                label += ': ';
-                            }
-                            if (context.parsed.y !== null) {
-                                label += context.parsed.y.toFixed(2);
-                            }
-                            return label;
-                        }
-                    }
+            y: {
+                title: {
+                    display: true,
+                    text: yTitle
                 }
             }
-        }
-    });
-
-    // Configuração para o gráfico de luminosidade e pressão
-    const lightPressureCtx = document.getElementById('light-pressure-chart').getContext('2d');
-    lightPressureChart = new Chart(lightPressureCtx, {
-        type: 'line',
-        data: {
-            datasets: [
-                {
-                    label: 'Luminosidade (lux)',
-                    borderColor: CHART_COLORS.light,
-                    backgroundColor: CHART_COLORS.light + '20',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    fill: true,
-                    data: [],
-                    yAxisID: 'y-light'
-                },
-                {
-                    label: 'Pressão (hPa)',
-                    borderColor: CHART_COLORS.pressure,
-                    backgroundColor: CHART_COLORS.pressure + '20',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    fill: true,
-                    data: [],
-                    yAxisID: 'y-pressure'
-                }
-            ]
         },
-        options: {
-            responsive: true,
-            maintainAspectRatio: false,
-            interaction: {
-                mode: 'index',
-                intersect: false
+        plugins: {
+            legend: {
+                position: 'top',
             },
-            scales: {
-                x: {
-                    type: 'time',
-                    time: {
-                        unit: 'minute',
-                        displayFormats: {
-                            minute: 'HH:mm:ss'
+            tooltip: {
+                callbacks: {
+                    label: function(context) {
+                        let label = context.dataset.label || '';
+                        if (label) {
+                            label += ': ';
                         }
-                    },
-                    title: {
-                        display: true,
-                        text: 'Hora'
-                    }
-                },
-                'y-light': {
-                    type: 'linear',
-                    position: 'left',
-                    title: {
-                        display: true,
-                        text: 'Luminosidade (lux)'
-                    },
-                    grid: {
-                        drawOnChartArea: false
-                    }
-                },
-                'y-pressure': {
-                    type: 'linear',
-                    position: 'right',
-                    title: {
-                        display: true,
-                        text: 'Pressão (hPa)'
-                    }
-                }
-            },
-            plugins: {
-                legend: {
-                    position: 'top',
-                },
-                tooltip: {
-                    callbacks: {
-                        label: function(context) {
-                            let label = context.dataset.label || '';
-                            if (label) {
-                                label += ': ';
-                            }
-                            if (context.parsed.y !== null) {
-                                label += context.parsed.y.toFixed(2);
-                            }
-                            return label;
+                        if (context.parsed.y !== null) {
+                            label += context.parsed.y.toFixed(2);
                         }
+                        return label;
                     }
                 }
             }
         }
+    };
+}
+
+// Função para inicializar gráficos
+function initCharts() {
+    // Um gráfico para cada tipo de sensor configurado
+    document.querySelectorAll('canvas.type-chart').forEach(canvas => {
+        const header = canvas.closest('.card').querySelector('.card-header');
+        typeCharts[canvas.dataset.sensorType] = new Chart(canvas.getContext('2d'), {
+            type: 'line',
+            data: { datasets: [] },
+            options: chartOptions(header ? header.textContent : 'Valor')
+        });
     });
 
     // Configuração para o gráfico histórico
     const historyCtx = document.getElementById('history-chart').getContext('2d');
     historyChart = new Chart(historyCtx, {
         type: 'line',
-        data: {
-            datasets: [
-                {
-                    label: 'Temperatura (°C)',
-                    borderColor: CHART_COLORS.temperature,
-                    backgroundColor: 'transparent',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    data: []
-                },
-                {
-                    label: 'Umidade (%)',
-                    borderColor: CHART_COLORS.humidity,
-                    backgroundColor: 'transparent',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    data: []
-                },
-                {
-                    label: 'Luminosidade (lux)',
-                    borderColor: CHART_COLORS.light,
-                    backgroundColor: 'transparent',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    data: []
-                },
-                {
-                    label: 'Pressão (hPa)',
-                    borderColor: CHART_COLORS.pressure,
-                    backgroundColor: 'transparent',
-                    borderWidth: 2,
-                    tension: 0.3,
-                    data: []
-                }
-            ]
-        },
-        options: {
-            responsive: true,
-            maintainAspectRatio: false,
-            interaction: {
-                mode: 'index',
-                intersect: false
-            },
-            scales: {
-                x: {
-                    type: 'time',
-                    time: {
-                        unit: 'minute',
-                        displayFormats: {
-                            minute: 'HH:mm:ss'
-                        }
-                    },
-                    title: {
-                        display: true,
-                        text: 'Hora'
-                    }
-                },
-                y: {
-                    title: {
-                        display: true,
-                        text: 'Valor'
-                    }
-                }
-            },
-            plugins: {
-                legend: {
-                    position: 'top',
-                },
-                tooltip: {
-                    callbacks: {
-                        label: function(context) {
-                            let label = context.dataset.label || '';
-                            if (label) {
-                                label += ': ';
-                            }
-                            if (context.parsed.y !== null) {
-                                label += context.parsed.y.toFixed(2);
-                            }
-                            return label;
-                        }
-                    }
-                }
-            }
-        }
+        data: { datasets: [] },
+        options: chartOptions('Valor')
     });
 }
 
+// Cria os conjuntos de dados de um sensor na primeira leitura recebida
+function registerSensor(reading) {
+    const sensor = {
+        type: reading.sensor_type,
+        unit: reading.unit,
+        points: []
+    };
+    sensorData[reading.sensor_id] = sensor;
+
+    const color = CHART_COLORS[(Object.keys(sensorData).length - 1) % CHART_COLORS.length];
+    const label = `${reading.sensor_id} (${reading.unit})`;
+
+    const typeChart = typeCharts[sensor.type];
+    if (typeChart) {
+        typeChart.data.datasets.push({
+            label: label,
+            sensorId: reading.sensor_id,
+            borderColor: color,
+            backgroundColor: color.replace('rgb', 'rgba').replace(')', ', 0.12)'),
+            borderWidth: 2,
+            tension: 0.3,
+            fill: true,
+            data: sensor.points
+        });
+    }
+
+    if (historyChart) {
+        historyChart.data.datasets.push({
+            label: label,
+            sensorId: reading.sensor_id,
+            borderColor: color,
+            backgroundColor: 'transparent',
+            borderWidth: 2,
+            tension: 0.3,
+            data: sensor.points
+        });
+    }
+    return sensor;
+}
+
 // Função para atualizar os valores exibidos nos cards
 function updateSensorValues(readings) {
     readings.forEach(reading => {
         const sensorType = reading.sensor_type;
         const sensorId = reading.sensor_id;
-        const value = reading.value.toFixed(2);
+        // Valores NaN/Inf chegam como texto
+        const numeric = typeof reading.value === 'number';
+        const value = numeric ? reading.value.toFixed(2) : String(reading.value);
         
         // Atualizar o valor no card
         const valueElement = document.getElementById(`value-${sensorType}-${sensorId}`);
         if (valueElement) {
             valueElement.textContent = value;
         }
-        
+
+        // Exibir a qualidade quando a leitura não for boa
+        const qualityElement = document.getElementById(`quality-${sensorType}-${sensorId}`);
+        if (qualityElement) {
+            const quality = reading.quality || 'Good';
+            qualityElement.textContent = quality === 'Good' ? '' : quality;
+            qualityElement.classList.toggle('text-warning', quality.startsWith('Uncertain'));
+            qualityElement.classList.toggle('text-danger', quality.startsWith('Bad'));
+        }
+
         // Armazenar dados para os gráficos
-        if (sensorData[sensorType]) {
-            sensorData[sensorType].push({
-                x: new Date(reading.timestamp),
-                y: reading.value
-            });
-            
-            // Limitar o número de pontos
-            if (sensorData[sensorType].length > MAX_DATA_POINTS) {
-                sensorData[sensorType].shift();
-            }
+        const sensor = sensorData[sensorId] || registerSensor(reading);
+        sensor.points.push({
+            x: new Date(reading.timestamp),
+            y: numeric ? reading.value : null
+        });
+        
+        // Limitar o número de pontos
+        if (sensor.points.length > MAX_DATA_POINTS) {
+            sensor.points.shift();
         }
     });
     
@@ -363,25 +224,17 @@ function updateSensorValues(readings) {
 
 // Função para atualizar os gráficos
 function updateCharts() {
-    if (tempHumidChart) {
-        tempHumidChart.data.datasets[0].data = sensorData.temperature;
-        tempHumidChart.data.datasets[1].data = sensorData.humidity;
-        tempHumidChart.update();
-    }
-    
-    if (lightPressureChart) {
-        lightPressureChart.data.datasets[0].data = sensorData.light;
-        lightPressureChart.data.datasets[1].data = sensorData.pressure;
-        lightPressureChart.update();
-    }
-    
+    const charts = Object.values(typeCharts);
     if (historyChart) {
-        historyChart.data.datasets[0].data = sensorData.temperature;
-        historyChart.data.datasets[1].data = sensorData.humidity;
-        historyChart.data.datasets[2].data = sensorData.light;
-        historyChart.data.datasets[3].data = sensorData.pressure;
-        historyChart.update();
+        charts.push(historyChart);
     }
+
+    charts.forEach(chart => {
+        chart.data.datasets.forEach(dataset => {
+            dataset.data = sensorData[dataset.sensorId].points;
+        });
+        chart.update();
+    });
 }
 
 // Função para buscar leituras mais recentes
@@ -401,6 +254,29 @@ async function fetchReadings() {
     }
 }
 
+// Assinatura dos sensores exibidos; muda quando sensores são adicionados, alterados ou removidos
+let sensorsSignature = null;
+
+// Recarrega a página quando a lista de sensores muda em execução
+async function checkSensors() {
+    try {
+        const response = await fetch('/api/sensors');
+        if (!response.ok) {
+            throw new Error('Falha ao buscar sensores');
+        }
+
+        const sensors = await response.json();
+        const signature = JSON.stringify(sensors);
+        if (sensorsSignature !== null && signature !== sensorsSignature) {
+            window.location.reload();
+            return;
+        }
+        sensorsSignature = signature;
+    } catch (error) {
+        console.error('Erro ao buscar sensores:', error);
+    }
+}
+
 // Função para atualizar status dos serviços
 function updateServiceStatus() {
     // Simulação do status dos serviços - em um cenário real isso viria da API
@@ -422,10 +298,12 @@ document.addEventListener('DOMContentLoaded', () => {
     // Primeira leitura
     fetchReadings();
     updateServiceStatus();
+    checkSensors();
     
     // Configurar atualização periódica
     setInterval(fetchReadings, UPDATE_INTERVAL);
     setInterval(updateServiceStatus, 5000); // 5 segundos
+    setInterval(checkSensors, 5000);
     
     // Adicionar botão de reset no header
     const container = document.querySelector('.container h2').parentNode;
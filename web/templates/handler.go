@@ -25,8 +25,9 @@ func NewHandler(sim *simulator.Simulator, config configs.AppConfig) *Handler {
 
 // HandleDashboard gerencia o endpoint do dashboard
 func (h *Handler) HandleDashboard(w http.ResponseWriter, r *http.Request) {
-	// Renderizar o template do dashboard
-	component := Dashboard(h.config.Sensors)
+	// Renderizar o template do dashboard com os sensores efetivos do simulador
+	// (modelos expandidos e unidades padrão preenchidas)
+	component := Dashboard(h.simulator.Configs())
 	err := component.Render(context.Background(), w)
 	if err != nil {
 		log.Printf("Erro ao renderizar dashboard: %v", err)